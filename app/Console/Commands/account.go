@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"context"
+	services "gonga/app/Services"
+	"gonga/bootstrap"
+	cloudinary "gonga/packages/Cloudinary"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// account:purge command
+func AccountPurgeCmd(app *bootstrap.Application) *cobra.Command {
+	return &cobra.Command{
+		Use:   "account:purge",
+		Short: "Purge deleted accounts",
+		Long:  "Permanently delete the accounts whose deletion grace period is over, including their content and uploaded media. The server runs this every hour.",
+		Args:  cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			purged, err := services.PurgeDueAccounts(context.Background(), app.DB, cloudinary.NewCloudinaryClient())
+			if err != nil {
+				pterm.Error.Printf("Purged %d accounts, some accounts failed and will be retried: %v\n", purged, err)
+				return
+			}
+			pterm.Success.Printf("Purged %d accounts.\n", purged)
+		},
+	}
+}
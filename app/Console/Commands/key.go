@@ -1,48 +1,215 @@
 package commands
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/base64"
-	"fmt"
+	"encoding/pem"
 	"gonga/bootstrap"
+	auth "gonga/packages/Auth"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/joho/godotenv"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
 
-// make:controller command
+// envValue is a single KEY=value line of the .env file.
+type envValue struct {
+	Key   string
+	Value string
+}
+
+// key:generate command
 func KeyGenerateCmd(app *bootstrap.Application) *cobra.Command {
+	var algorithm string
+	var rotate bool
+	var keyDir string
+	var show bool
 
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "key:generate",
 		Short: "Generate a new application key",
-		Long:  "Generate a new application key",
-		Args:  cobra.MinimumNArgs(0),
+		Long: "Generate a new key for signing access tokens and store it in the .env file.\n" +
+			"HS256 keys are stored in APP_KEY, RS256 and EdDSA keys are written to a PEM file referenced by JWT_PRIVATE_KEY.\n" +
+			"With --rotate the current key is kept for verifying tokens that were issued before the rotation.",
+		Args: cobra.ExactArgs(0),
 		Run: func(_ *cobra.Command, _ []string) {
-			// Generate 32 bytes of random data
-			key := make([]byte, 64)
-			_, err := rand.Read(key)
-			if err != nil {
-				return 
+			current, err := godotenv.Read(".env")
+			if err != nil && !os.IsNotExist(err) {
+				pterm.Error.Printf("Failed to read .env: %v\n", err)
+				return
+			}
+			if current == nil {
+				current = map[string]string{}
+			}
+			if algorithm == "" {
+				algorithm = current["JWT_ALGORITHM"]
+			}
+			if algorithm == "" {
+				algorithm = auth.HS256
 			}
 
-			// Encode the random data using base64
-			keyString := base64.StdEncoding.EncodeToString(key)
+			var values []envValue
+			if rotate {
+				values = append(values, previousKeyValues(current)...)
+			}
+
+			switch algorithm {
+			case auth.HS256:
+				// 64 bytes of random data, base64 encoded
+				secret := make([]byte, 64)
+				if _, err := rand.Read(secret); err != nil {
+					pterm.Error.Printf("Failed to generate key: %v\n", err)
+					return
+				}
+				keyString := base64.StdEncoding.EncodeToString(secret)
+				if show {
+					pterm.Info.Println(keyString)
+					return
+				}
+				values = append(values, envValue{"JWT_ALGORITHM", auth.HS256}, envValue{"APP_KEY", keyString})
 
-			// Output the new key
-			pterm.Info.Printf("Application key [%s] generated successfully.\n", keyString)
-			// Append the new key to the .env file
-			file, err := os.OpenFile(".env", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-			if err != nil {
-				return 
+			case auth.RS256, auth.EdDSA:
+				data, err := generatePrivateKey(algorithm)
+				if err != nil {
+					pterm.Error.Printf("Failed to generate key: %v\n", err)
+					return
+				}
+				if show {
+					pterm.Println(string(data))
+					return
+				}
+				key, err := auth.ParsePEMKey(algorithm, data)
+				if err != nil {
+					pterm.Error.Printf("Failed to generate key: %v\n", err)
+					return
+				}
+				path := filepath.Join(keyDir, key.ID+".pem")
+				if err := os.MkdirAll(keyDir, 0700); err != nil {
+					pterm.Error.Printf("Failed to create %s: %v\n", keyDir, err)
+					return
+				}
+				if err := os.WriteFile(path, data, 0600); err != nil {
+					pterm.Error.Printf("Failed to write %s: %v\n", path, err)
+					return
+				}
+				pterm.Info.Printf("Private key written to %s.\n", path)
+				values = append(values, envValue{"JWT_ALGORITHM", algorithm}, envValue{"JWT_PRIVATE_KEY", path})
+
+			default:
+				pterm.Error.Printf("Unsupported algorithm %q, use HS256, RS256 or EdDSA.\n", algorithm)
+				return
 			}
-			defer file.Close()
 
-			_, err = fmt.Fprintf(file, "\nAPP_KEY=%s\n", keyString)
-			if err != nil {
-				return 
+			if err := writeEnvValues(".env", values); err != nil {
+				pterm.Error.Printf("Failed to update .env: %v\n", err)
+				return
+			}
+			if rotate {
+				pterm.Success.Printf("Application key rotated, %s tokens signed with the previous key stay valid until they expire.\n", algorithm)
+			} else {
+				pterm.Success.Printf("Application key set successfully, existing %s tokens are no longer valid.\n", algorithm)
 			}
-        },
+		},
+	}
+
+	cmd.Flags().StringVarP(&algorithm, "algorithm", "a", "", "Signing algorithm: HS256, RS256 or EdDSA, defaults to JWT_ALGORITHM")
+	cmd.Flags().BoolVar(&rotate, "rotate", false, "Keep the current key for verifying existing tokens")
+	cmd.Flags().StringVar(&keyDir, "key-dir", "storage/keys", "Directory for RS256 and EdDSA private keys")
+	cmd.Flags().BoolVar(&show, "show", false, "Print the key instead of modifying .env")
+
+	return cmd
+}
+
+// previousKeyValues moves the signing key configured in the .env file to the list of previous keys.
+func previousKeyValues(current map[string]string) []envValue {
+	if algorithm := current["JWT_ALGORITHM"]; algorithm == "" || algorithm == auth.HS256 {
+		if secret := current["APP_KEY"]; secret != "" {
+			return []envValue{{"APP_PREVIOUS_KEYS", prependListItem(current["APP_PREVIOUS_KEYS"], secret)}}
+		}
+	} else if path := current["JWT_PRIVATE_KEY"]; path != "" {
+		return []envValue{{"JWT_PREVIOUS_KEYS", prependListItem(current["JWT_PREVIOUS_KEYS"], path)}}
+	}
+	return nil
+}
+
+func prependListItem(list, item string) string {
+	items := []string{item}
+	for _, existing := range strings.Split(list, ",") {
+		if existing = strings.TrimSpace(existing); existing != "" && existing != item {
+			items = append(items, existing)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// generatePrivateKey returns a new PKCS #8 PEM encoded private key for the algorithm.
+func generatePrivateKey(algorithm string) ([]byte, error) {
+	var private interface{}
+	var err error
+	if algorithm == auth.RS256 {
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	} else {
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// writeEnvValues replaces the lines of the keys in the env file and appends the keys it does not contain yet.
+// Duplicate lines of a key are removed.
+func writeEnvValues(path string, values []envValue) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(content) == 0 {
+		lines = nil
+	}
+	written := map[string]bool{}
+	var output []string
+
+	for _, line := range lines {
+		name := strings.TrimSpace(strings.SplitN(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=", 2)[0])
+		value, ok := lookupEnvValue(values, name)
+		if !ok || !strings.Contains(line, "=") {
+			output = append(output, line)
+			continue
+		}
+		if !written[name] {
+			output = append(output, name+"="+value)
+			written[name] = true
+		}
+	}
+	for _, v := range values {
+		if !written[v.Key] {
+			output = append(output, v.Key+"="+v.Value)
+			written[v.Key] = true
+		}
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(output, "\n")+"\n"), 0644)
+}
+
+func lookupEnvValue(values []envValue, key string) (string, bool) {
+	for _, v := range values {
+		if v.Key == key {
+			return v.Value, true
+		}
 	}
+	return "", false
 }
@@ -30,6 +30,21 @@ func MigrateCmd(app *bootstrap.Application) *cobra.Command {
 				&Models.PasswordReset{},
 				&Models.PersonalAccessToken{},
 				&Models.Mention{},
+				&Models.TwoFactorRecoveryCode{},
+				&Models.ProviderIdentity{},
+				&Models.OAuthState{},
+				&Models.LoginAttempt{},
+				&Models.EmailChange{},
+				&Models.DataExport{},
+				&Models.Block{},
+				&Models.Mute{},
+				&Models.ModerationCase{},
+				&Models.Report{},
+				&Models.ModerationAction{},
+				&Models.MutedWord{},
+				&Models.Appeal{},
+				&Models.VerificationEvent{},
+				&Models.IdempotencyKey{},
 			)
 			if err != nil {
 				log.Fatalf("Error running migrations: %v", err)
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"bufio"
+	"gonga/bootstrap"
+	"gonga/config"
+	bloom "gonga/packages/Bloom"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// password:breached command
+func PasswordBreachedCmd(app *bootstrap.Application) *cobra.Command {
+	var output string
+	var falsePositiveRate float64
+
+	cmd := &cobra.Command{
+		Use:   "password:breached [wordlist]",
+		Short: "Build the breached password filter",
+		Long:  "Build the bloom filter used by the password policy from a list of breached passwords, one password per line.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if output == "" {
+				output = config.LoadPasswordConfig().BreachedListPath
+			}
+
+			// The list is read twice, first to size the filter and then to fill it
+			count, err := eachLine(args[0], func(string) {})
+			if err != nil {
+				pterm.Error.Printf("Failed to read %s: %v\n", args[0], err)
+				return
+			}
+
+			filter := bloom.New(count, falsePositiveRate)
+			if _, err := eachLine(args[0], filter.Add); err != nil {
+				pterm.Error.Printf("Failed to read %s: %v\n", args[0], err)
+				return
+			}
+
+			if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+				pterm.Error.Printf("Failed to create %s: %v\n", filepath.Dir(output), err)
+				return
+			}
+			file, err := os.Create(output)
+			if err != nil {
+				pterm.Error.Printf("Failed to create %s: %v\n", output, err)
+				return
+			}
+			defer file.Close()
+
+			if _, err := filter.WriteTo(file); err != nil {
+				pterm.Error.Printf("Failed to write %s: %v\n", output, err)
+				return
+			}
+
+			pterm.Success.Printf("Breached password filter with %d passwords written to %s.\n", count, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Filter file, defaults to PASSWORD_BREACHED_FILTER")
+	cmd.Flags().Float64Var(&falsePositiveRate, "false-positive-rate", 0.001, "Acceptable false positive rate")
+
+	return cmd
+}
+
+// eachLine calls fn for every non-empty line of the file and returns the number of lines.
+func eachLine(path string, fn func(string)) (uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var count uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		fn(line)
+		count++
+	}
+	return count, scanner.Err()
+}
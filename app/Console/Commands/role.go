@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"gonga/app/Models"
+	"gonga/bootstrap"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// user:role command
+func UserRoleCmd(app *bootstrap.Application) *cobra.Command {
+	return &cobra.Command{
+		Use:   "user:role [username] [role]",
+		Short: "Change the role of a user",
+		Long:  "Change the role of a user to user, moderator or admin. Use it to create the first admin.",
+		Args:  cobra.ExactArgs(2),
+		Run: func(_ *cobra.Command, args []string) {
+			username, role := args[0], Models.Role(args[1])
+			if !role.IsValid() {
+				pterm.Error.Printf("Unknown role %q, expected one of %v.\n", role, Models.Roles)
+				return
+			}
+
+			var user Models.User
+			if err := app.DB.Where("username = ?", username).First(&user).Error; err != nil {
+				pterm.Error.Printf("User %s not found.\n", username)
+				return
+			}
+
+			if err := app.DB.Model(&user).Update("role", role).Error; err != nil {
+				pterm.Error.Printf("Failed to update the role: %v\n", err)
+				return
+			}
+
+			pterm.Success.Printf("%s is now %s.\n", username, role)
+		},
+	}
+}
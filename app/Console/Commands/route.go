@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"encoding/json"
+	"gonga/bootstrap"
+	"gonga/packages"
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// route:list command
+func RouteListCmd(app *bootstrap.Application) *cobra.Command {
+	var method string
+	var path string
+	var middleware string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "route:list",
+		Short: "List the registered routes",
+		Long: "List the method, path, name, handler and middleware of every registered route.\n" +
+			"The middleware is listed in the order it runs, without the middleware every route passes.\n" +
+			"Prefix --middleware with ! to list the routes without it, like --middleware '!AuthMiddleware'.",
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			routes := []packages.RouteInfo{}
+			for _, route := range app.Router.Routes() {
+				if method != "" && !strings.EqualFold(route.Method, method) {
+					continue
+				}
+				if path != "" && !strings.HasPrefix(route.Path, path) {
+					continue
+				}
+				if middleware != "" && !hasMiddleware(route, middleware) {
+					continue
+				}
+				routes = append(routes, route)
+			}
+
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(routes); err != nil {
+					pterm.Error.Printf("Failed to encode the routes: %v\n", err)
+				}
+				return
+			}
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+
+			// Set table headers
+			t.AppendHeader(table.Row{"Method", "Path", "Name", "Handler", "Middleware"})
+
+			for _, route := range routes {
+				t.AppendRow(table.Row{route.Method, route.Path, route.Name, route.Handler, strings.Join(route.Middleware, ", ")})
+			}
+
+			// Render the table
+			t.Render()
+		},
+	}
+
+	cmd.Flags().StringVarP(&method, "method", "m", "", "Only list the routes with this HTTP method")
+	cmd.Flags().StringVarP(&path, "path", "p", "", "Only list the routes whose path starts with this prefix")
+	cmd.Flags().StringVar(&middleware, "middleware", "", "Only list the routes with this middleware, like AuthMiddleware")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the routes as JSON")
+
+	return cmd
+}
+
+// hasMiddleware reports whether the route passes the middleware, or doesn't when the filter starts with "!".
+// The filter matches the name of the middleware without its package, case insensitively.
+func hasMiddleware(route packages.RouteInfo, filter string) bool {
+	negate := strings.HasPrefix(filter, "!")
+	filter = strings.TrimPrefix(filter, "!")
+
+	found := false
+	for _, name := range route.Middleware {
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if strings.EqualFold(name, filter) {
+			found = true
+			break
+		}
+	}
+	return found != negate
+}
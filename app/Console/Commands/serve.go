@@ -3,6 +3,7 @@ package commands
 import (
 	"gonga/bootstrap"
 
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
 
@@ -13,7 +14,9 @@ func ServeCmd(app *bootstrap.Application) *cobra.Command {
 		Args:  cobra.ExactArgs(0),
 		Run: func(_ *cobra.Command, _ []string) {
 			// start server
-			app.Run()
+			if err := app.Run(); err != nil {
+				pterm.Fatal.Println(err)
+			}
 		},
 	}
 }
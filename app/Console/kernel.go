@@ -16,6 +16,7 @@ var rootCmd = &cobra.Command{
 func Run(app *bootstrap.Application) error {
 	rootCmd.AddCommand(commands.AboutCmd(app))
 	rootCmd.AddCommand(commands.ListCmd(app))
+	rootCmd.AddCommand(commands.RouteListCmd(app))
 	rootCmd.AddCommand(commands.EnvCmd(app))
 	rootCmd.AddCommand(commands.DbCmd(app))
 	//make commands
@@ -25,6 +26,9 @@ func Run(app *bootstrap.Application) error {
 	rootCmd.AddCommand(commands.MigrateCmd(app))
 	rootCmd.AddCommand(commands.MakeMiddlewareCmd(app))
 	rootCmd.AddCommand(commands.KeyGenerateCmd(app))
+	rootCmd.AddCommand(commands.PasswordBreachedCmd(app))
+	rootCmd.AddCommand(commands.UserRoleCmd(app))
+	rootCmd.AddCommand(commands.AccountPurgeCmd(app))
 	rootCmd.AddCommand(commands.DocGenerateCmd(app))
 
 	rootCmd.AddCommand(commands.ServeCmd(app))
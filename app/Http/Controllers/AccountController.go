@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"errors"
+	requests "gonga/app/Http/Requests"
+	"gonga/app/Models"
+	services "gonga/app/Services"
+	auth "gonga/packages/Auth"
+	password "gonga/packages/Password"
+	"gonga/utils"
+	"log"
+	"net/http"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// AccountController manages the credentials of the authenticated user.
+type AccountController struct {
+	DB *gorm.DB
+}
+
+// UpdatePassword handles the PUT /me/password request to change the authenticated user's password.
+//
+// The current password is required. After the change every other session of the user is logged out,
+// the session that made the request stays active.
+//
+//	@Summary		Change password
+//	@Description	Changes the password of the authenticated user and revokes their other sessions
+//	@Tags			Account
+//	@Accept			json
+//	@Produce		json
+//	@Param			updatePasswordRequest	body		requests.UpdatePasswordRequest	true	"Current and new password"
+//	@Success		200						{object}	utils.SwaggerSuccessResponse
+//	@Failure		400						{object}	utils.SwaggerErrorResponse
+//	@Failure		401						{object}	utils.SwaggerErrorResponse
+//	@Failure		500						{object}	utils.SwaggerErrorResponse
+//	@Router			/me/password [put]
+//	@Security		BearerAuth
+func (c AccountController) UpdatePassword(w http.ResponseWriter, r *http.Request) {
+	principal, err := auth.Current(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var req requests.UpdatePasswordRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	var user Models.User
+	if err := c.DB.First(&user, principal.UserID).Error; err != nil {
+		utils.HandleError(w, errors.New("user not found"), http.StatusNotFound)
+		return
+	}
+
+	if err := utils.VerifyPassword(user.Password, req.CurrentPassword); err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, utils.ErrorResponse{
+			Errors: map[string]string{"CurrentPassword": utils.Validation["current_password"].(string)},
+		})
+		return
+	}
+	if err := password.ValidateRequest(w, "Password", req.Password, user.Username, user.Email); err != nil {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		return services.RevokeOtherSessions(tx, user.ID, principal.TokenID)
+	})
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "password updated, other sessions have been logged out",
+	})
+}
+
+// UpdateEmail handles the PUT /me/email request to change the authenticated user's email address.
+//
+// The current password is required. A confirmation link is sent to the new address and a notice to the
+// current one. The email address only changes once the link is confirmed with POST /me/email/confirm.
+//
+//	@Summary		Change email
+//	@Description	Sends a confirmation link to the new email address of the authenticated user
+//	@Tags			Account
+//	@Accept			json
+//	@Produce		json
+//	@Param			updateEmailRequest	body		requests.UpdateEmailRequest	true	"New email and current password"
+//	@Success		202					{object}	utils.SwaggerSuccessResponse
+//	@Failure		400					{object}	utils.SwaggerErrorResponse
+//	@Failure		401					{object}	utils.SwaggerErrorResponse
+//	@Failure		409					{object}	utils.SwaggerErrorResponse
+//	@Failure		500					{object}	utils.SwaggerErrorResponse
+//	@Router			/me/email [put]
+//	@Security		BearerAuth
+func (c AccountController) UpdateEmail(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var req requests.UpdateEmailRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	var user Models.User
+	if err := c.DB.First(&user, userID).Error; err != nil {
+		utils.HandleError(w, errors.New("user not found"), http.StatusNotFound)
+		return
+	}
+
+	if err := utils.VerifyPassword(user.Password, req.Password); err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, utils.ErrorResponse{
+			Errors: map[string]string{"Password": utils.Validation["current_password"].(string)},
+		})
+		return
+	}
+
+	if err := services.RequestEmailChange(c.DB, &user, req.Email); err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailTaken):
+			utils.HandleError(w, err, http.StatusConflict)
+		case errors.Is(err, services.ErrEmailUnchanged):
+			utils.HandleError(w, err, http.StatusBadRequest)
+		default:
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusAccepted, utils.APIResponse{
+		Type:    "success",
+		Message: "a confirmation link has been sent to the new email address",
+	})
+}
+
+// ConfirmEmail handles the POST /me/email/confirm request to apply a pending email change.
+//
+// The token is the one from the confirmation link sent to the new address, so this endpoint
+// does not require an access token.
+//
+//	@Summary		Confirm email change
+//	@Description	Switches the user's email address to the confirmed new address
+//	@Tags			Account
+//	@Accept			json
+//	@Produce		json
+//	@Param			confirmEmailRequest	body		requests.ConfirmEmailRequest	true	"Confirmation token"
+//	@Success		200					{object}	utils.SwaggerSuccessResponse
+//	@Failure		400					{object}	utils.SwaggerErrorResponse
+//	@Failure		409					{object}	utils.SwaggerErrorResponse
+//	@Failure		500					{object}	utils.SwaggerErrorResponse
+//	@Router			/me/email/confirm [post]
+func (c AccountController) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
+	var req requests.ConfirmEmailRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	if _, err := services.ConfirmEmailChange(c.DB, req.Token); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidEmailChange):
+			utils.HandleError(w, err, http.StatusBadRequest)
+		case errors.Is(err, services.ErrEmailTaken):
+			utils.HandleError(w, err, http.StatusConflict)
+		default:
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "email address updated",
+	})
+}
+
+// RequestExport handles the POST /me/export request to export the authenticated user's personal data.
+//
+// The archive is built in the background. Once it is ready the user gets an email with a download link
+// that expires after EXPORT_LINK_LIFETIME_HOURS.
+//
+//	@Summary		Export personal data
+//	@Description	Builds a ZIP archive of the authenticated user's data and emails a download link
+//	@Tags			Account
+//	@Produce		json
+//	@Success		202	{object}	utils.SwaggerSuccessResponse
+//	@Failure		401	{object}	utils.SwaggerErrorResponse
+//	@Failure		409	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/me/export [post]
+//	@Security		BearerAuth
+func (c AccountController) RequestExport(w http.ResponseWriter, r *http.Request) {
+	user, err := auth.User(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	export, err := services.RequestDataExport(c.DB, user)
+	if err != nil {
+		if errors.Is(err, services.ErrExportInProgress) {
+			utils.HandleError(w, err, http.StatusConflict)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusAccepted, utils.APIResponse{
+		Type:    "success",
+		Message: "your data export is being prepared, you will receive an email with a download link",
+		Data:    export,
+	})
+}
+
+// DownloadExport handles the GET /exports/{token} request to download a data export.
+//
+// The token is the one from the emailed download link, so this endpoint does not require an access token.
+//
+//	@Summary		Download data export
+//	@Description	Downloads the ZIP archive of a data export
+//	@Tags			Account
+//	@Produce		application/zip
+//	@Param			token	path		string	true	"Download token"
+//	@Success		200		{file}		file
+//	@Failure		404		{object}	utils.SwaggerErrorResponse
+//	@Failure		500		{object}	utils.SwaggerErrorResponse
+//	@Router			/exports/{token} [get]
+func (c AccountController) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	token, err := utils.GetParam(r, "token")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	export, err := services.FindDataExport(c.DB, token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidExportLink) {
+			utils.HandleError(w, err, http.StatusNotFound)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	file, err := os.Open(export.Path)
+	if err != nil {
+		utils.HandleError(w, services.ErrInvalidExportLink, http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="data-export.zip"`)
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(w, r, "data-export.zip", *export.CompletedAt, file)
+}
@@ -0,0 +1,111 @@
+package admin
+
+import (
+	"errors"
+	"gonga/app/Models"
+	policies "gonga/app/Policies"
+	auth "gonga/packages/Auth"
+	"gonga/utils"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// CommentController is the admin API for moderating comments.
+type CommentController struct {
+	DB *gorm.DB
+}
+
+// Index handles the GET /admin/comments request to list comments of all users.
+//
+//	@Summary		List comments
+//	@Description	Lists comments of all users
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			user_id		query		int	false	"Only comments of this user"
+//	@Param			page		query		int	false	"Page number for pagination"
+//	@Param			per_page	query		int	false	"Number of items per page"
+//	@Success		200			{object}	utils.SwaggerPagination
+//	@Failure		401			{object}	utils.SwaggerErrorResponse
+//	@Failure		403			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/comments [get]
+//	@Security		BearerAuth
+func (c CommentController) Index(w http.ResponseWriter, r *http.Request) {
+	var comments []Models.Comment
+	var response utils.APIResponse
+
+	query := c.DB.Model(&Models.Comment{})
+	if userID := r.URL.Query().Get("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	paginationScope, err := utils.Paginate(r, query, &comments, &response, "User")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to paginate comments")
+		return
+	}
+
+	if err := paginationScope(query).Find(&comments).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to retrieve comments")
+		return
+	}
+
+	response.Data = comments
+	response.Type = "success"
+	response.Message = "data retrieved successfully"
+
+	utils.JSONResponse(w, http.StatusOK, response)
+}
+
+// Delete handles the DELETE /admin/comments/{id} request to remove a comment of any user.
+//
+//	@Summary		Delete comment
+//	@Description	Deletes a comment of any user
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int	true	"Comment ID"
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
+//	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/comments/{id} [delete]
+//	@Security		BearerAuth
+func (c CommentController) Delete(w http.ResponseWriter, r *http.Request) {
+	commentID, err := utils.GetParam(r, "id")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := auth.User(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var comment Models.Comment
+	if err := c.DB.First(&comment, commentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.HandleError(w, errors.New("comment not found"), http.StatusNotFound)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if !policies.Can(user, policies.Delete, &comment) {
+		utils.HandleError(w, errors.New("you are not authorized to delete this comment"), http.StatusForbidden)
+		return
+	}
+
+	if err := c.DB.Delete(&comment).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "comment deleted",
+	})
+}
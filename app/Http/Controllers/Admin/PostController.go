@@ -0,0 +1,111 @@
+package admin
+
+import (
+	"errors"
+	"gonga/app/Models"
+	policies "gonga/app/Policies"
+	auth "gonga/packages/Auth"
+	"gonga/utils"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// PostController is the admin API for moderating posts.
+type PostController struct {
+	DB *gorm.DB
+}
+
+// Index handles the GET /admin/posts request to list posts of all users.
+//
+//	@Summary		List posts
+//	@Description	Lists posts of all users regardless of their visibility
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			user_id		query		int	false	"Only posts of this user"
+//	@Param			page		query		int	false	"Page number for pagination"
+//	@Param			per_page	query		int	false	"Number of items per page"
+//	@Success		200			{object}	utils.SwaggerPagination
+//	@Failure		401			{object}	utils.SwaggerErrorResponse
+//	@Failure		403			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/posts [get]
+//	@Security		BearerAuth
+func (c PostController) Index(w http.ResponseWriter, r *http.Request) {
+	var posts []Models.Post
+	var response utils.APIResponse
+
+	query := c.DB.Model(&Models.Post{})
+	if userID := r.URL.Query().Get("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	paginationScope, err := utils.Paginate(r, query, &posts, &response, "User")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to paginate posts")
+		return
+	}
+
+	if err := paginationScope(query).Find(&posts).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to retrieve posts")
+		return
+	}
+
+	response.Data = posts
+	response.Type = "success"
+	response.Message = "data retrieved successfully"
+
+	utils.JSONResponse(w, http.StatusOK, response)
+}
+
+// Delete handles the DELETE /admin/posts/{id} request to remove a post of any user.
+//
+//	@Summary		Delete post
+//	@Description	Deletes a post of any user
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int	true	"Post ID"
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
+//	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/posts/{id} [delete]
+//	@Security		BearerAuth
+func (c PostController) Delete(w http.ResponseWriter, r *http.Request) {
+	postID, err := utils.GetParam(r, "id")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := auth.User(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var post Models.Post
+	if err := c.DB.First(&post, postID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.HandleError(w, errors.New("post not found"), http.StatusNotFound)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if !policies.Can(user, policies.Delete, &post) {
+		utils.HandleError(w, errors.New("you are not authorized to delete this post"), http.StatusForbidden)
+		return
+	}
+
+	if err := c.DB.Delete(&post).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "post deleted",
+	})
+}
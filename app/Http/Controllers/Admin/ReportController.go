@@ -0,0 +1,348 @@
+package admin
+
+import (
+	"errors"
+	requests "gonga/app/Http/Requests/Admin"
+	"gonga/app/Models"
+	policies "gonga/app/Policies"
+	services "gonga/app/Services"
+	auth "gonga/packages/Auth"
+	"gonga/utils"
+	"log"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// ReportController is the admin API for the moderation queue.
+type ReportController struct {
+	DB *gorm.DB
+}
+
+// Index handles the GET /admin/reports request to list the moderation queue.
+//
+// Open cases come first, the most reported first.
+//
+//	@Summary		List reports
+//	@Description	Lists the moderation cases, each collecting the reports of one post, comment or user, or an appeal
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			status			query		string	false	"open (default) or resolved"
+//	@Param			subject_type	query		string	false	"Only cases about posts, comments, users or appeals"
+//	@Param			assignee_id		query		int		false	"Only cases claimed by this moderator, 0 for unclaimed cases"
+//	@Param			page			query		int		false	"Page number for pagination"
+//	@Param			per_page		query		int		false	"Number of items per page"
+//	@Success		200				{object}	utils.SwaggerPagination
+//	@Failure		401				{object}	utils.SwaggerErrorResponse
+//	@Failure		403				{object}	utils.SwaggerErrorResponse
+//	@Failure		500				{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/reports [get]
+//	@Security		BearerAuth
+func (c ReportController) Index(w http.ResponseWriter, r *http.Request) {
+	var cases []Models.ModerationCase
+	var response utils.APIResponse
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = string(Models.CaseOpen)
+	}
+	query := c.DB.Model(&Models.ModerationCase{}).Where("status = ?", status)
+	if subjectType := r.URL.Query().Get("subject_type"); subjectType != "" {
+		query = query.Where("subject_type = ?", subjectType)
+	}
+	if assigneeID := r.URL.Query().Get("assignee_id"); assigneeID == "0" {
+		query = query.Where("assignee_id IS NULL")
+	} else if assigneeID != "" {
+		query = query.Where("assignee_id = ?", assigneeID)
+	}
+
+	paginationScope, err := utils.Paginate(r, query, &cases, &response)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to paginate reports")
+		return
+	}
+
+	if err := paginationScope(query.Order("report_count desc")).Find(&cases).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to retrieve reports")
+		return
+	}
+
+	response.Data = cases
+	response.Type = "success"
+	response.Message = "data retrieved successfully"
+
+	utils.JSONResponse(w, http.StatusOK, response)
+}
+
+// Show handles the GET /admin/reports/{id} request to show a moderation case with its reports or appeal and audit log.
+//
+//	@Summary		Show report
+//	@Description	Shows a moderation case with its reports or appeal and the actions taken
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int	true	"Case ID"
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
+//	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/reports/{id} [get]
+//	@Security		BearerAuth
+func (c ReportController) Show(w http.ResponseWriter, r *http.Request) {
+	moderationCase, ok := c.findCase(w, r, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Reports").Preload("Appeal").Preload("Actions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		})
+	})
+	if !ok {
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type: "success",
+		Data: moderationCase,
+	})
+}
+
+// Claim handles the POST /admin/reports/{id}/claim request to take a case from the queue.
+//
+// Only the moderator who claimed a case can resolve it, until they release it.
+//
+//	@Summary		Claim report
+//	@Description	Assigns a moderation case to the authenticated moderator
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int	true	"Case ID"
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
+//	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		409	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/reports/{id}/claim [post]
+//	@Security		BearerAuth
+func (c ReportController) Claim(w http.ResponseWriter, r *http.Request) {
+	moderatorID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	moderationCase, ok := c.findCase(w, r, nil)
+	if !ok {
+		return
+	}
+
+	if err := services.ClaimCase(c.DB, moderationCase, moderatorID); err != nil {
+		handleModerationError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "report claimed",
+		Data:    moderationCase,
+	})
+}
+
+// Release handles the DELETE /admin/reports/{id}/claim request to return a claimed case to the queue.
+//
+//	@Summary		Release report
+//	@Description	Returns a moderation case claimed by the authenticated moderator to the queue
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int	true	"Case ID"
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
+//	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		409	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/reports/{id}/claim [delete]
+//	@Security		BearerAuth
+func (c ReportController) Release(w http.ResponseWriter, r *http.Request) {
+	moderatorID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	moderationCase, ok := c.findCase(w, r, nil)
+	if !ok {
+		return
+	}
+
+	if err := services.ReleaseCase(c.DB, moderationCase, moderatorID); err != nil {
+		handleModerationError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "report released",
+		Data:    moderationCase,
+	})
+}
+
+// Resolve handles the POST /admin/reports/{id}/resolve request to close a case.
+//
+// The action is one of dismiss, remove (posts and comments only), warn or suspend. A note is required
+// for warnings, it is the text emailed to the user. Suspending requires the permission to suspend the user.
+// Appeals are resolved with dismiss, which keeps the account state, or reinstate, which requires the
+// permission to lift the appealed state.
+//
+//	@Summary		Resolve report
+//	@Description	Resolves a moderation case by dismissing it, removing the content, warning, suspending or reinstating the user
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		int								true	"Case ID"
+//	@Param			resolveReportRequest	body		requests.ResolveReportRequest	true	"Action and note"
+//	@Success		200						{object}	utils.SwaggerSuccessResponse
+//	@Failure		400						{object}	utils.SwaggerErrorResponse
+//	@Failure		403						{object}	utils.SwaggerErrorResponse
+//	@Failure		404						{object}	utils.SwaggerErrorResponse
+//	@Failure		409						{object}	utils.SwaggerErrorResponse
+//	@Failure		500						{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/reports/{id}/resolve [post]
+//	@Security		BearerAuth
+func (c ReportController) Resolve(w http.ResponseWriter, r *http.Request) {
+	var req requests.ResolveReportRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	moderator, err := auth.User(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	moderationCase, ok := c.findCase(w, r, nil)
+	if !ok {
+		return
+	}
+
+	// The subject user may have been purged since the report
+	var subjectUser *Models.User
+	var user Models.User
+	if err := c.DB.First(&user, moderationCase.SubjectUserID).Error; err == nil {
+		subjectUser = &user
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	resolution := Models.Resolution(req.Action)
+	if resolution == Models.ResolutionSuspend && subjectUser != nil && !policies.Can(moderator, policies.Suspend, subjectUser) {
+		utils.HandleError(w, errors.New("you are not authorized to suspend this user"), http.StatusForbidden)
+		return
+	}
+	if resolution == Models.ResolutionReinstate && subjectUser != nil {
+		action := policies.Suspend
+		if subjectUser.IsBanned() {
+			action = policies.Ban
+		}
+		if !policies.Can(moderator, action, subjectUser) {
+			utils.HandleError(w, errors.New("you are not authorized to reinstate this user"), http.StatusForbidden)
+			return
+		}
+	}
+
+	if err := services.ResolveCase(c.DB, moderationCase, moderator.ID, subjectUser, resolution, req.Note); err != nil {
+		handleModerationError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "report resolved",
+		Data:    moderationCase,
+	})
+}
+
+// AuditLog handles the GET /admin/moderation-log request to list the actions taken on reports.
+//
+//	@Summary		Moderation audit log
+//	@Description	Lists the actions moderators and the system took on moderation cases, newest first
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			moderator_id	query		int	false	"Only actions of this moderator, 0 for automatic actions"
+//	@Param			case_id			query		int	false	"Only actions on this case"
+//	@Param			page			query		int	false	"Page number for pagination"
+//	@Param			per_page		query		int	false	"Number of items per page"
+//	@Success		200				{object}	utils.SwaggerPagination
+//	@Failure		401				{object}	utils.SwaggerErrorResponse
+//	@Failure		403				{object}	utils.SwaggerErrorResponse
+//	@Failure		500				{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/moderation-log [get]
+//	@Security		BearerAuth
+func (c ReportController) AuditLog(w http.ResponseWriter, r *http.Request) {
+	var actions []Models.ModerationAction
+	var response utils.APIResponse
+
+	query := c.DB.Model(&Models.ModerationAction{})
+	if moderatorID := r.URL.Query().Get("moderator_id"); moderatorID != "" {
+		query = query.Where("moderator_id = ?", moderatorID)
+	}
+	if caseID := r.URL.Query().Get("case_id"); caseID != "" {
+		query = query.Where("case_id = ?", caseID)
+	}
+
+	paginationScope, err := utils.Paginate(r, query, &actions, &response)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to paginate the moderation log")
+		return
+	}
+
+	if err := paginationScope(query.Order("id desc")).Find(&actions).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to retrieve the moderation log")
+		return
+	}
+
+	response.Data = actions
+	response.Type = "success"
+	response.Message = "data retrieved successfully"
+
+	utils.JSONResponse(w, http.StatusOK, response)
+}
+
+// findCase loads the moderation case of the {id} route parameter. It writes the error response and
+// returns false if there is none.
+func (c ReportController) findCase(w http.ResponseWriter, r *http.Request, scope func(*gorm.DB) *gorm.DB) (*Models.ModerationCase, bool) {
+	id, err := utils.GetParam(r, "id")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusBadRequest)
+		return nil, false
+	}
+
+	query := c.DB
+	if scope != nil {
+		query = query.Scopes(scope)
+	}
+	var moderationCase Models.ModerationCase
+	if err := query.First(&moderationCase, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.HandleError(w, errors.New("report not found"), http.StatusNotFound)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	return &moderationCase, true
+}
+
+func handleModerationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrCaseResolved), errors.Is(err, services.ErrCaseClaimed):
+		utils.HandleError(w, err, http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidResolution):
+		utils.HandleError(w, err, http.StatusBadRequest)
+	default:
+		utils.HandleError(w, err, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,479 @@
+package admin
+
+import (
+	"errors"
+	requests "gonga/app/Http/Requests/Admin"
+	"gonga/app/Models"
+	policies "gonga/app/Policies"
+	services "gonga/app/Services"
+	auth "gonga/packages/Auth"
+	"gonga/utils"
+	"log"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// UserController is the admin API for managing users.
+type UserController struct {
+	DB *gorm.DB
+}
+
+// Index handles the GET /admin/users request to list users.
+//
+//	@Summary		List users
+//	@Description	Lists all users, optionally filtered by role, account state or a username/email search
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			role		query		string	false	"Only users with this role"
+//	@Param			state		query		string	false	"Only users with this account state: active, restricted, suspended or banned"
+//	@Param			suspended	query		bool	false	"Only suspended or banned (true) or other (false) users"
+//	@Param			badge		query		string	false	"Only users with this verification badge"
+//	@Param			search		query		string	false	"Username or email contains"
+//	@Param			page		query		int		false	"Page number for pagination"
+//	@Param			per_page	query		int		false	"Number of items per page"
+//	@Success		200			{object}	utils.SwaggerPagination
+//	@Failure		401			{object}	utils.SwaggerErrorResponse
+//	@Failure		403			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/users [get]
+//	@Security		BearerAuth
+func (c UserController) Index(w http.ResponseWriter, r *http.Request) {
+	var users []Models.User
+	var response utils.APIResponse
+
+	query := c.DB.Model(&Models.User{})
+	if role := r.URL.Query().Get("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if state := r.URL.Query().Get("state"); state != "" {
+		query = query.Where("state = ?", state)
+	}
+	suspended := []Models.AccountState{Models.AccountSuspended, Models.AccountBanned}
+	switch r.URL.Query().Get("suspended") {
+	case "true":
+		query = query.Where("state IN ?", suspended)
+	case "false":
+		query = query.Where("state NOT IN ?", suspended)
+	}
+	if badge := r.URL.Query().Get("badge"); badge != "" {
+		query = query.Where("badge = ?", badge)
+	}
+	if search := r.URL.Query().Get("search"); search != "" {
+		like := "%" + search + "%"
+		query = query.Where("username LIKE ? OR email LIKE ?", like, like)
+	}
+
+	paginationScope, err := utils.Paginate(r, query, &users, &response)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to paginate users")
+		return
+	}
+
+	if err := paginationScope(query).Find(&users).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to retrieve users")
+		return
+	}
+
+	response.Data = users
+	response.Type = "success"
+	response.Message = "data retrieved successfully"
+
+	utils.JSONResponse(w, http.StatusOK, response)
+}
+
+// Suspend handles the POST /admin/users/{id}/suspend request to suspend a user.
+//
+// Suspended users are logged out and can't log in again until the suspension expires or is lifted.
+// Without expires_at the suspension lasts until it is lifted. Moderators can only suspend regular users.
+//
+//	@Summary		Suspend user
+//	@Description	Suspends a user and revokes all of their sessions
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int							true	"User ID"
+//	@Param			suspendUserRequest	body		requests.SuspendUserRequest	true	"Suspension reason"
+//	@Success		200					{object}	utils.SwaggerSuccessResponse
+//	@Failure		400					{object}	utils.SwaggerErrorResponse
+//	@Failure		403					{object}	utils.SwaggerErrorResponse
+//	@Failure		404					{object}	utils.SwaggerErrorResponse
+//	@Failure		500					{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/users/{id}/suspend [post]
+//	@Security		BearerAuth
+func (c UserController) Suspend(w http.ResponseWriter, r *http.Request) {
+	var req requests.SuspendUserRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	target, ok := c.authorizeTarget(w, r, policies.Suspend)
+	if !ok {
+		return
+	}
+
+	if err := services.SuspendUser(c.DB, target, req.Reason, req.ExpiresAt); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "user suspended",
+	})
+}
+
+// Unsuspend handles the DELETE /admin/users/{id}/suspend request to lift a suspension.
+//
+// Lifting a ban requires the permission to ban the user.
+//
+//	@Summary		Unsuspend user
+//	@Description	Lifts the suspension, restriction or ban of a user
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
+//	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/users/{id}/suspend [delete]
+//	@Security		BearerAuth
+func (c UserController) Unsuspend(w http.ResponseWriter, r *http.Request) {
+	target, ok := c.authorizeTarget(w, r, policies.Suspend)
+	if !ok {
+		return
+	}
+	if target.IsBanned() && !c.authorize(w, r, policies.Ban, target) {
+		return
+	}
+
+	if err := services.UnsuspendUser(c.DB, target); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "user unsuspended",
+	})
+}
+
+// UpdateState handles the PUT /admin/users/{id}/state request to change the account state of a user.
+//
+// Restricted users can log in and read, suspended users can't log in and banned users can't log in
+// and their content is hidden. The user is emailed about the change and can appeal it. Banning a user
+// or lifting a ban requires the permission to ban the user.
+//
+//	@Summary		Change account state
+//	@Description	Restricts, suspends, bans or reinstates a user, optionally until expires_at
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		int									true	"User ID"
+//	@Param			updateAccountStateRequest	body		requests.UpdateAccountStateRequest	true	"New state"
+//	@Success		200							{object}	utils.SwaggerSuccessResponse
+//	@Failure		400							{object}	utils.SwaggerErrorResponse
+//	@Failure		403							{object}	utils.SwaggerErrorResponse
+//	@Failure		404							{object}	utils.SwaggerErrorResponse
+//	@Failure		500							{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/users/{id}/state [put]
+//	@Security		BearerAuth
+func (c UserController) UpdateState(w http.ResponseWriter, r *http.Request) {
+	var req requests.UpdateAccountStateRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	target, ok := c.authorizeTarget(w, r, policies.Suspend)
+	if !ok {
+		return
+	}
+	state := Models.AccountState(req.State)
+	if (state == Models.AccountBanned || target.IsBanned()) && !c.authorize(w, r, policies.Ban, target) {
+		return
+	}
+
+	if err := services.ChangeAccountState(c.DB, target, state, req.Reason, req.ExpiresAt); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "account state updated",
+		Data:    target,
+	})
+}
+
+// GrantBadge handles the PUT /admin/users/{id}/badge request to verify a user.
+//
+// The badge replaces the badge the user had. It is revoked automatically when the user changes their username.
+//
+//	@Summary		Grant verification badge
+//	@Description	Gives a user the notable, organization or staff verification badge
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int							true	"User ID"
+//	@Param			grantBadgeRequest	body		requests.GrantBadgeRequest	true	"Badge and note"
+//	@Success		200					{object}	utils.SwaggerSuccessResponse
+//	@Failure		400					{object}	utils.SwaggerErrorResponse
+//	@Failure		403					{object}	utils.SwaggerErrorResponse
+//	@Failure		404					{object}	utils.SwaggerErrorResponse
+//	@Failure		500					{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/users/{id}/badge [put]
+//	@Security		BearerAuth
+func (c UserController) GrantBadge(w http.ResponseWriter, r *http.Request) {
+	var req requests.GrantBadgeRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	target, ok := c.authorizeTarget(w, r, policies.Verify)
+	if !ok {
+		return
+	}
+	actorID, _ := auth.ID(r)
+
+	if err := services.GrantBadge(c.DB, target, Models.BadgeType(req.Badge), actorID, req.Note); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "badge granted",
+		Data:    target,
+	})
+}
+
+// RevokeBadge handles the DELETE /admin/users/{id}/badge request to remove the verification badge of a user.
+//
+//	@Summary		Revoke verification badge
+//	@Description	Removes the verification badge of a user
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
+//	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/users/{id}/badge [delete]
+//	@Security		BearerAuth
+func (c UserController) RevokeBadge(w http.ResponseWriter, r *http.Request) {
+	target, ok := c.authorizeTarget(w, r, policies.Verify)
+	if !ok {
+		return
+	}
+	actorID, _ := auth.ID(r)
+
+	if err := services.RevokeBadge(c.DB, target, actorID, ""); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "badge revoked",
+		Data:    target,
+	})
+}
+
+// Verifications handles the GET /admin/users/{id}/verifications request to list the verification history of a user.
+//
+//	@Summary		Verification history
+//	@Description	Lists the badges granted to and revoked from a user, newest first
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id			path		int	true	"User ID"
+//	@Param			page		query		int	false	"Page number for pagination"
+//	@Param			per_page	query		int	false	"Number of items per page"
+//	@Success		200			{object}	utils.SwaggerPagination
+//	@Failure		403			{object}	utils.SwaggerErrorResponse
+//	@Failure		404			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/users/{id}/verifications [get]
+//	@Security		BearerAuth
+func (c UserController) Verifications(w http.ResponseWriter, r *http.Request) {
+	target, ok := c.authorizeTarget(w, r, policies.Verify)
+	if !ok {
+		return
+	}
+
+	var events []Models.VerificationEvent
+	var response utils.APIResponse
+
+	query := c.DB.Model(&Models.VerificationEvent{}).Where("user_id = ?", target.ID)
+	paginationScope, err := utils.Paginate(r, query, &events, &response)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to paginate the verification history")
+		return
+	}
+
+	if err := paginationScope(query.Order("id desc")).Find(&events).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to retrieve the verification history")
+		return
+	}
+
+	response.Data = events
+	response.Type = "success"
+	response.Message = "data retrieved successfully"
+
+	utils.JSONResponse(w, http.StatusOK, response)
+}
+
+// UpdateRole handles the PUT /admin/users/{id}/role request to change the role of a user.
+//
+//	@Summary		Change user role
+//	@Description	Changes the role of a user to user, moderator or admin
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id					path		int							true	"User ID"
+//	@Param			updateRoleRequest	body		requests.UpdateRoleRequest	true	"New role"
+//	@Success		200					{object}	utils.SwaggerSuccessResponse
+//	@Failure		400					{object}	utils.SwaggerErrorResponse
+//	@Failure		403					{object}	utils.SwaggerErrorResponse
+//	@Failure		404					{object}	utils.SwaggerErrorResponse
+//	@Failure		500					{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/users/{id}/role [put]
+//	@Security		BearerAuth
+func (c UserController) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	var req requests.UpdateRoleRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	target, ok := c.authorizeTarget(w, r, policies.ChangeRole)
+	if !ok {
+		return
+	}
+
+	if err := c.DB.Model(target).Update("role", Models.Role(req.Role)).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "role updated",
+	})
+}
+
+// Delete handles the DELETE /admin/users/{id} request to delete a user.
+//
+//	@Summary		Delete user
+//	@Description	Deletes a user and revokes all of their sessions
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
+//	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/admin/users/{id} [delete]
+//	@Security		BearerAuth
+func (c UserController) Delete(w http.ResponseWriter, r *http.Request) {
+	target, ok := c.authorizeTarget(w, r, policies.Delete)
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteUser(c.DB, target); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "user deleted",
+	})
+}
+
+// authorizeTarget loads the user from the {id} route parameter and checks that the authenticated user
+// may perform the action on them. It writes the error response and returns false otherwise.
+func (c UserController) authorizeTarget(w http.ResponseWriter, r *http.Request, action string) (*Models.User, bool) {
+	id, err := utils.GetParam(r, "id")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusBadRequest)
+		return nil, false
+	}
+
+	authUser, err := auth.User(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return nil, false
+	}
+
+	var target Models.User
+	if err := c.DB.First(&target, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.HandleError(w, errors.New("user not found"), http.StatusNotFound)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+
+	if !policies.Can(authUser, action, &target) {
+		utils.HandleError(w, errors.New("you are not authorized to "+action+" this user"), http.StatusForbidden)
+		return nil, false
+	}
+
+	return &target, true
+}
+
+// authorize checks that the authenticated user may perform the action on the target. It writes the
+// error response and returns false otherwise.
+func (c UserController) authorize(w http.ResponseWriter, r *http.Request, action string, target *Models.User) bool {
+	authUser, err := auth.User(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return false
+	}
+	if !policies.Can(authUser, action, target) {
+		utils.HandleError(w, errors.New("you are not authorized to "+action+" this user"), http.StatusForbidden)
+		return false
+	}
+	return true
+}
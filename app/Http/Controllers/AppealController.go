@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	requests "gonga/app/Http/Requests"
+	"gonga/app/Models"
+	services "gonga/app/Services"
+	auth "gonga/packages/Auth"
+	"gonga/utils"
+	"log"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// AppealController lets restricted, suspended and banned users appeal the state of their account.
+type AppealController struct {
+	DB *gorm.DB
+}
+
+// Create handles the POST /appeals request to appeal the state of the caller's account.
+//
+// Restricted users authenticate as usual. Suspended and banned users can't log in, they send the
+// appeal token returned by the login instead. The appeal is added to the moderation queue.
+//
+//	@Summary		Appeal account state
+//	@Description	Appeals the restriction, suspension or ban of the caller's account
+//	@Tags			Appeals
+//	@Accept			json
+//	@Produce		json
+//	@Param			createAppealRequest	body		requests.CreateAppealRequest	true	"Appeal message"
+//	@Success		201					{object}	utils.SwaggerSuccessResponse
+//	@Failure		400					{object}	utils.SwaggerErrorResponse
+//	@Failure		401					{object}	utils.SwaggerErrorResponse
+//	@Failure		409					{object}	utils.SwaggerErrorResponse
+//	@Failure		500					{object}	utils.SwaggerErrorResponse
+//	@Router			/appeals [post]
+//	@Security		BearerAuth
+func (c AppealController) Create(w http.ResponseWriter, r *http.Request) {
+	var req requests.CreateAppealRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	userID, err := auth.ID(r)
+	if err != nil {
+		if req.AppealToken == "" {
+			utils.HandleError(w, err, http.StatusUnauthorized)
+			return
+		}
+		if userID, err = auth.ParseAppealToken(req.AppealToken); err != nil {
+			utils.HandleError(w, err, http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var user Models.User
+	if err := c.DB.First(&user, userID).Error; err != nil {
+		utils.HandleError(w, errors.New("unauthorized"), http.StatusUnauthorized)
+		return
+	}
+
+	appeal, err := services.FileAppeal(c.DB, &user, req.Message)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNothingToAppeal):
+			utils.HandleError(w, err, http.StatusBadRequest)
+		case errors.Is(err, services.ErrAppealPending):
+			utils.HandleError(w, err, http.StatusConflict)
+		default:
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, utils.APIResponse{
+		Type:    "success",
+		Message: "your appeal has been submitted",
+		Data:    appeal,
+	})
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	authn "gonga/packages/Auth"
+	"gonga/utils"
+	"net/http"
+)
+
+// KeySetController publishes the public keys that access tokens are signed with.
+type KeySetController struct{}
+
+// Index handles the GET /.well-known/jwks.json request to list the token verification keys.
+//
+// Other services use this endpoint to verify our access tokens. Keys that were rotated out stay in
+// the set until they are removed from the configuration. HS256 secrets are never published.
+//
+//	@Summary		JSON Web Key Set
+//	@Description	Returns the public keys used to verify access tokens
+//	@Tags			Authentication
+//	@Produce		json
+//	@Success		200	{object}	auth.JSONWebKeySet
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/.well-known/jwks.json [get]
+func (c KeySetController) Index(w http.ResponseWriter, r *http.Request) {
+	keys, err := authn.Keys()
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.JSONResponse(w, http.StatusOK, keys.JWKS())
+}
@@ -4,9 +4,15 @@ import (
 	"errors"
 	requests "gonga/app/Http/Requests/Auth"
 	responses "gonga/app/Http/Responses/Auth"
+	"gonga/app/Models"
+	services "gonga/app/Services"
+	"gonga/config"
+	authn "gonga/packages/Auth"
 	"gonga/utils"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 
 	"gorm.io/gorm"
 )
@@ -36,6 +42,8 @@ func (c LoginController) Show(w http.ResponseWriter, r *http.Request) {
 //	@Success		200				{object}	responses.LoginResponse
 //	@Failure		400				{object}	utils.SwaggerErrorResponse
 //	@Failure		401				{object}	utils.SwaggerErrorResponse
+//	@Failure		403				{object}	responses.AccountStateResponse
+//	@Failure		429				{object}	utils.SwaggerErrorResponse
 //	@Failure		500				{object}	utils.SwaggerErrorResponse
 //	@Router			/login [post]
 func (c LoginController) Create(w http.ResponseWriter, r *http.Request) {
@@ -60,15 +68,70 @@ func (c LoginController) Create(w http.ResponseWriter, r *http.Request) {
 	if err := utils.ValidateRequest(w, &user); err != nil {
 		return
 	}
+	// Refuse the attempt while the username or client IP is backed off or locked out
+	lockout := config.LoadAuthConfig().Lockout
+	attempt, ok := c.reserveAttempt(w, r, lockout, user.Username)
+	if !ok {
+		return
+	}
+	defer services.ReleaseLoginAttempt(c.DB, attempt)
+
 	// Check credentials and get user ID from database
 	userID, err := utils.Authenticate(user.Username, user.Password, c.DB)
 	if err != nil {
+		var attempted *Models.User
+		var existing Models.User
+		if c.DB.Where("username = ?", user.Username).Limit(1).Find(&existing).Error == nil && existing.ID != 0 {
+			attempted = &existing
+		}
+		if err := services.FinishLoginAttempt(c.DB, lockout, attempt, attempted, false, "invalid credentials"); err != nil {
+			log.Println(err.Error())
+		}
 		utils.HandleError(w, errors.New("invalid username or password"), http.StatusUnauthorized)
 		return
 	}
 
+	var account Models.User
+	if err := c.DB.First(&account, userID).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if account.IsSuspended() {
+		writeSuspended(w, &account)
+		return
+	}
+
+	// Users with two-factor authentication get a challenge instead of an access token.
+	// The login only counts as successful once the second factor is verified.
+	if account.HasTwoFactorEnabled() {
+		challenge, err := authn.GenerateTwoFactorChallengeToken(account.ID)
+		if err != nil {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, responses.TwoFactorChallengeResponse{
+			TwoFactor:      true,
+			ChallengeToken: challenge,
+			Message:        "two-factor authentication required",
+		})
+		return
+	}
+
+	if err := services.FinishLoginAttempt(c.DB, lockout, attempt, &account, true, ""); err != nil {
+		log.Println(err.Error())
+	}
+
+	// Logging in during the grace period cancels a requested account deletion
+	message := "Login successful"
+	if cancelled, err := services.CancelAccountDeletion(c.DB, &account); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	} else if cancelled {
+		message = "Login successful, your account deletion has been cancelled"
+	}
+
 	// Generate JWT token
-	token, err := utils.GenerateToken(userID)
+	token, err := services.IssueAccessToken(c.DB, uint(userID), r)
 	if err != nil {
 		utils.HandleError(w, err, http.StatusInternalServerError)
 		return
@@ -78,17 +141,181 @@ func (c LoginController) Create(w http.ResponseWriter, r *http.Request) {
 	response := responses.LoginResponse{
 		Token:   token,
 		UserID:  userID,
-		Message: "Login successful",
+		Message: message,
 	}
 	utils.JSONResponse(w, http.StatusOK, response)
 }
 
+// TwoFactor handles the POST /login/2fa request to finish a login with a two-factor code.
+//
+// The challenge token returned by POST /login has to be sent along with either a TOTP code
+// or one of the user's single-use recovery codes.
+//
+//	@Summary		Two-factor login
+//	@Description	Completes a login for users with two-factor authentication enabled
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			twoFactorRequest	body		requests.TwoFactorLoginRequest	true	"Challenge token and code"
+//	@Success		200					{object}	responses.LoginResponse
+//	@Failure		400					{object}	utils.SwaggerErrorResponse
+//	@Failure		401					{object}	utils.SwaggerErrorResponse
+//	@Failure		403					{object}	responses.AccountStateResponse
+//	@Failure		429					{object}	utils.SwaggerErrorResponse
+//	@Failure		500					{object}	utils.SwaggerErrorResponse
+//	@Router			/login/2fa [post]
+func (c LoginController) TwoFactor(w http.ResponseWriter, r *http.Request) {
+	var twoFactorReq requests.TwoFactorLoginRequest
+
+	if err := utils.DecodeJSONBody(w, r, &twoFactorReq); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &twoFactorReq); err != nil {
+		return
+	}
+
+	userID, err := authn.ParseTwoFactorChallengeToken(twoFactorReq.ChallengeToken)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var account Models.User
+	if err := c.DB.First(&account, userID).Error; err != nil || !account.HasTwoFactorEnabled() {
+		utils.HandleError(w, errors.New("invalid or expired two-factor challenge"), http.StatusUnauthorized)
+		return
+	}
+	if account.IsSuspended() {
+		writeSuspended(w, &account)
+		return
+	}
+
+	// Two-factor codes are throttled like passwords, a 6 digit code is easy to brute-force otherwise
+	lockout := config.LoadAuthConfig().Lockout
+	attempt, ok := c.reserveAttempt(w, r, lockout, account.Username)
+	if !ok {
+		return
+	}
+	defer services.ReleaseLoginAttempt(c.DB, attempt)
+
+	if twoFactorReq.RecoveryCode != "" {
+		if !services.UseRecoveryCode(c.DB, account.ID, twoFactorReq.RecoveryCode) {
+			if err := services.FinishLoginAttempt(c.DB, lockout, attempt, &account, false, "invalid recovery code"); err != nil {
+				log.Println(err.Error())
+			}
+			utils.HandleError(w, errors.New("invalid recovery code"), http.StatusUnauthorized)
+			return
+		}
+	} else if err := services.VerifyTwoFactorCode(c.DB, &account, twoFactorReq.Code); err != nil {
+		if err := services.FinishLoginAttempt(c.DB, lockout, attempt, &account, false, "invalid two-factor code"); err != nil {
+			log.Println(err.Error())
+		}
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	if err := services.FinishLoginAttempt(c.DB, lockout, attempt, &account, true, ""); err != nil {
+		log.Println(err.Error())
+	}
+
+	// Logging in during the grace period cancels a requested account deletion
+	message := "Login successful"
+	if cancelled, err := services.CancelAccountDeletion(c.DB, &account); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	} else if cancelled {
+		message = "Login successful, your account deletion has been cancelled"
+	}
+
+	token, err := services.IssueAccessToken(c.DB, userID, r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, responses.LoginResponse{
+		Token:   token,
+		UserID:  int(userID),
+		Message: message,
+	})
+}
+
+// reserveAttempt records the login attempt before its credentials are checked. It writes a 429
+// response with a Retry-After header and returns false when the username or client IP has to wait
+// before the next login attempt.
+func (c LoginController) reserveAttempt(w http.ResponseWriter, r *http.Request, lockout config.LockoutConfig, username string) (*Models.LoginAttempt, bool) {
+	attempt, retryAfter, err := services.ReserveLoginAttempt(c.DB, lockout, r, username)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return nil, false
+	}
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		utils.HandleError(w, errors.New("too many login attempts, please try again later"), http.StatusTooManyRequests)
+		return nil, false
+	}
+	return attempt, true
+}
+
 func (c LoginController) Update(w http.ResponseWriter, r *http.Request) {
 	// Handle PUT /logincontroller/{id} request
 	// You can get the request body by reading from r.Body
 	// You can send a response by writing to w
 }
 
+// Delete handles the POST /logout request to end the current session.
+//
+// The access token used for this request is revoked. Other sessions of the user stay active.
+//
+//	@Summary		User logout
+//	@Description	Revokes the access token of the current session
+//	@Tags			Authentication
+//	@Produce		json
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		401	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/logout [post]
+//	@Security		BearerAuth
 func (c LoginController) Delete(w http.ResponseWriter, r *http.Request) {
-	// Handle DELETE /logincontroller/{id} request
+	principal, err := authn.Current(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	sessionID := principal.TokenID
+
+	if err := services.RevokeSession(c.DB, sessionID); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "logged out",
+	})
+}
+
+// writeSuspended responds to the login of a suspended or banned user with the state of the account and
+// a token to appeal it.
+func writeSuspended(w http.ResponseWriter, user *Models.User) {
+	appealToken, err := authn.GenerateAppealToken(user.ID)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	state := user.CurrentState()
+	utils.JSONResponse(w, http.StatusForbidden, responses.AccountStateResponse{
+		State:       string(state),
+		Reason:      user.StateReason,
+		ExpiresAt:   user.StateExpiresAt,
+		AppealToken: appealToken,
+		Message:     "this account has been " + string(state),
+	})
 }
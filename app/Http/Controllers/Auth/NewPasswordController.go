@@ -1,7 +1,16 @@
 package auth
 
 import (
+	"crypto/subtle"
+	"errors"
+	requests "gonga/app/Http/Requests/Auth"
+	"gonga/app/Models"
+	services "gonga/app/Services"
+	password "gonga/packages/Password"
+	"gonga/utils"
+	"log"
 	"net/http"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -18,10 +27,86 @@ func (c NewPasswordController) Show(w http.ResponseWriter, r *http.Request) {
 	// Handle GET /newpasswordcontroller/{id} request
 }
 
+// Create handles the POST /reset-password request to set a new password with a reset token.
+//
+// The token is the one sent by POST /forgot-password. It can only be used once and the new password
+// has to satisfy the password policy.
+//
+//	@Summary		Reset password
+//	@Description	Sets a new password using a password reset token
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			newPasswordRequest	body		requests.NewPasswordRequest	true	"Reset token and new password"
+//	@Success		200					{object}	utils.SwaggerSuccessResponse
+//	@Failure		400					{object}	utils.SwaggerErrorResponse
+//	@Failure		500					{object}	utils.SwaggerErrorResponse
+//	@Router			/reset-password [post]
 func (c NewPasswordController) Create(w http.ResponseWriter, r *http.Request) {
-	// Handle POST /newpasswordcontroller request
-	// You can get the request body by reading from r.Body
-	// You can send a response by writing to w
+	var req requests.NewPasswordRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	// Unknown emails and wrong tokens get the same answer so the endpoint can't be used to probe accounts
+	invalidToken := errors.New("this password reset token is invalid")
+	var passwordReset Models.PasswordReset
+	if err := c.DB.Where("email = ?", req.Email).First(&passwordReset).Error; err != nil {
+		utils.HandleError(w, invalidToken, http.StatusBadRequest)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(passwordReset.Token), []byte(req.Token)) != 1 ||
+		time.Now().Unix() > passwordReset.Expiry {
+		utils.HandleError(w, invalidToken, http.StatusBadRequest)
+		return
+	}
+
+	var user Models.User
+	if err := c.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
+		utils.HandleError(w, invalidToken, http.StatusBadRequest)
+		return
+	}
+
+	if err := password.ValidateRequest(w, "Password", req.Password, user.Username, user.Email); err != nil {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Update the password and burn the token together, so a token can never be used twice.
+	// Whoever knew the old password is logged out everywhere.
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		if err := services.RevokeOtherSessions(tx, user.ID, ""); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&passwordReset).Error
+	})
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "password has been reset",
+	})
 }
 
 func (c NewPasswordController) Update(w http.ResponseWriter, r *http.Request) {
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	requests "gonga/app/Http/Requests/Auth"
 	"gonga/app/Models"
+	"gonga/packages"
 	mail "gonga/packages/Mail"
 	"gonga/utils"
+	"html"
 	"log"
 	"net/http"
+	"net/url"
 	"time"
 
 	"gorm.io/gorm"
@@ -107,9 +110,10 @@ func (c PasswordResetLinkController) Delete(w http.ResponseWriter, r *http.Reque
 func sendPasswordResetEmail(email, token string) error {
 	// Create a new password reset email
 	// Define email content
-	resetLink := fmt.Sprintf("https://example.com/reset_password?token=%s", token)
+	// The link opens the reset form of the frontend, which asks for the email address and the new password
+	resetLink := packages.FrontendURL("/reset-password", url.Values{"token": {token}})
 	textContent := fmt.Sprintf("Click on the following link to reset your password: %s", resetLink)
-	htmlContent := fmt.Sprintf("<p>Click <a href=\"%s\">here</a> to reset your password</p>", resetLink)
+	htmlContent := fmt.Sprintf("<p>Click <a href=\"%s\">here</a> to reset your password</p>", html.EscapeString(resetLink))
 	resetEmail := &mail.Mailable{
 		To: []string{email},
 		Content: struct {
@@ -5,6 +5,8 @@ import (
 	requests "gonga/app/Http/Requests/Auth"
 	responses "gonga/app/Http/Responses/Auth"
 	"gonga/app/Models"
+	services "gonga/app/Services"
+	password "gonga/packages/Password"
 	"gonga/utils"
 	"log"
 	"net/http"
@@ -56,6 +58,9 @@ func (c RegisterController) Create(w http.ResponseWriter, r *http.Request) {
 	if err := utils.ValidateRequest(w, &user); err != nil {
 		return
 	}
+	if err := password.ValidateRequest(w, "Password", user.Password, user.Username, user.Email); err != nil {
+		return
+	}
 
 	// Create user in database
 	hashedPassword, err := utils.HashPassword(user.Password)
@@ -76,7 +81,7 @@ func (c RegisterController) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate JWT token
-	token, err := utils.GenerateToken(int(newUser.ID))
+	token, err := services.IssueAccessToken(c.DB, newUser.ID, r)
 	if err != nil {
 		utils.HandleError(w, err, http.StatusInternalServerError)
 		return
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"errors"
+	responses "gonga/app/Http/Responses/Auth"
+	services "gonga/app/Services"
+	authn "gonga/packages/Auth"
+	oauth "gonga/packages/OAuth"
+	"gonga/utils"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// oauthStateLifetime is how long a user has to finish the login at the provider.
+const oauthStateLifetime = 10 * time.Minute
+
+type SocialLoginController struct {
+	DB        *gorm.DB
+	Providers *oauth.Manager
+}
+
+// Redirect handles the GET /auth/{provider}/redirect request to start a social login.
+//
+// This endpoint creates the state, PKCE verifier and nonce of a new authorization-code flow and
+// returns the provider URL the client has to open. The state is also set in an HttpOnly cookie,
+// only the browser that started the login can finish it.
+//
+//	@Summary		Start social login
+//	@Description	Returns the authorization URL of the given OAuth2/OpenID Connect provider
+//	@Tags			Authentication
+//	@Produce		json
+//	@Param			provider	path		string	true	"Provider name"
+//	@Success		200			{object}	responses.SocialRedirectResponse
+//	@Failure		404			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/auth/{provider}/redirect [get]
+func (c SocialLoginController) Redirect(w http.ResponseWriter, r *http.Request) {
+	providerName, err := utils.GetParam(r, "provider")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	provider, err := c.Providers.Provider(providerName)
+	if err != nil {
+		if errors.Is(err, oauth.ErrUnknownProvider) {
+			utils.HandleError(w, err, http.StatusNotFound)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	authURL, login, err := oauth.Begin(provider, services.OAuthStateStore{DB: c.DB}, oauthStateLifetime)
+	if err != nil {
+		log.Println(err.Error())
+		utils.HandleError(w, errors.New("the provider is not available"), http.StatusBadGateway)
+		return
+	}
+	oauth.SetStateCookie(w, login, secureCookies(r))
+
+	utils.JSONResponse(w, http.StatusOK, responses.SocialRedirectResponse{
+		URL:   authURL,
+		State: login.State,
+	})
+}
+
+// Callback handles the GET /auth/{provider}/callback request the provider redirects back to.
+//
+// The state is single-use and has to match the state cookie set by the redirect. The authorization code is exchanged with the PKCE verifier, the identity is
+// verified and linked to a user, and an access token is returned just like POST /login.
+//
+//	@Summary		Finish social login
+//	@Description	Exchanges the authorization code and logs the linked user in
+//	@Tags			Authentication
+//	@Produce		json
+//	@Param			provider	path		string	true	"Provider name"
+//	@Param			code		query		string	true	"Authorization code"
+//	@Param			state		query		string	true	"State returned by the redirect endpoint"
+//	@Success		200			{object}	responses.LoginResponse
+//	@Failure		400			{object}	utils.SwaggerErrorResponse
+//	@Failure		401			{object}	utils.SwaggerErrorResponse
+//	@Failure		403			{object}	responses.AccountStateResponse
+//	@Failure		404			{object}	utils.SwaggerErrorResponse
+//	@Failure		409			{object}	utils.SwaggerErrorResponse
+//	@Failure		502			{object}	utils.SwaggerErrorResponse
+//	@Router			/auth/{provider}/callback [get]
+func (c SocialLoginController) Callback(w http.ResponseWriter, r *http.Request) {
+	providerName, err := utils.GetParam(r, "provider")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	provider, err := c.Providers.Provider(providerName)
+	if err != nil {
+		if errors.Is(err, oauth.ErrUnknownProvider) {
+			utils.HandleError(w, err, http.StatusNotFound)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	query := r.URL.Query()
+	if providerError := query.Get("error"); providerError != "" {
+		utils.HandleError(w, errors.New("the provider denied the login: "+providerError), http.StatusUnauthorized)
+		return
+	}
+	code, stateParam := query.Get("code"), query.Get("state")
+	if code == "" || stateParam == "" {
+		utils.HandleError(w, errors.New("missing code or state"), http.StatusBadRequest)
+		return
+	}
+
+	// The state is consumed, whatever the outcome the login has to start over
+	oauth.ClearStateCookie(w, secureCookies(r))
+	identity, err := oauth.Complete(r.Context(), provider, services.OAuthStateStore{DB: c.DB}, stateParam, oauth.StateCookieValue(r), code)
+	if err != nil {
+		switch {
+		case errors.Is(err, oauth.ErrInvalidState):
+			utils.HandleError(w, err, http.StatusUnauthorized)
+		case errors.Is(err, oauth.ErrExchange):
+			log.Println(err.Error())
+			utils.HandleError(w, oauth.ErrExchange, http.StatusBadGateway)
+		case errors.Is(err, oauth.ErrIdentity):
+			log.Println(err.Error())
+			utils.HandleError(w, oauth.ErrIdentity, http.StatusUnauthorized)
+		default:
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	user, err := services.ResolveProviderIdentity(c.DB, provider.Name(), identity)
+	if err != nil {
+		if errors.Is(err, services.ErrUnverifiedEmail) || errors.Is(err, services.ErrUnverifiedAccount) {
+			utils.HandleError(w, err, http.StatusConflict)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if user.IsSuspended() {
+		writeSuspended(w, user)
+		return
+	}
+
+	// Social login must not bypass two-factor authentication
+	if user.HasTwoFactorEnabled() {
+		challenge, err := authn.GenerateTwoFactorChallengeToken(user.ID)
+		if err != nil {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, responses.TwoFactorChallengeResponse{
+			TwoFactor:      true,
+			ChallengeToken: challenge,
+			Message:        "two-factor authentication required",
+		})
+		return
+	}
+
+	// Logging in during the grace period cancels a requested account deletion
+	message := "Login successful"
+	if cancelled, err := services.CancelAccountDeletion(c.DB, user); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	} else if cancelled {
+		message = "Login successful, your account deletion has been cancelled"
+	}
+
+	accessToken, err := services.IssueAccessToken(c.DB, user.ID, r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, responses.LoginResponse{
+		Token:   accessToken,
+		UserID:  int(user.ID),
+		Message: message,
+	})
+}
+
+// secureCookies reports whether cookies should only be sent over HTTPS.
+func secureCookies(r *http.Request) bool {
+	return r.TLS != nil || strings.HasPrefix(utils.Env("APP_URL", ""), "https://")
+}
@@ -0,0 +1,292 @@
+package auth
+
+import (
+	"errors"
+	requests "gonga/app/Http/Requests/Auth"
+	responses "gonga/app/Http/Responses/Auth"
+	"gonga/app/Models"
+	services "gonga/app/Services"
+	"gonga/config"
+	authn "gonga/packages/Auth"
+	"gonga/utils"
+	"log"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorController struct {
+	DB *gorm.DB
+}
+
+// Create handles the POST /user/two-factor-authentication request to start a two-factor enrollment.
+//
+// This endpoint generates a new TOTP secret for the authenticated user and returns the provisioning URI
+// that authenticator apps can scan. Two-factor authentication is only enabled once the enrollment is confirmed.
+//
+//	@Summary		Enable two-factor authentication
+//	@Description	Generates a TOTP secret and provisioning URI for the authenticated user
+//	@Tags			Authentication
+//	@Produce		json
+//	@Success		200	{object}	responses.TwoFactorSetupResponse
+//	@Failure		401	{object}	utils.SwaggerErrorResponse
+//	@Failure		409	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/user/two-factor-authentication [post]
+//	@Security		BearerAuth
+func (c TwoFactorController) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := authn.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var user Models.User
+	if err := c.DB.First(&user, userID).Error; err != nil {
+		utils.HandleError(w, errors.New("user not found"), http.StatusNotFound)
+		return
+	}
+
+	if user.HasTwoFactorEnabled() {
+		utils.HandleError(w, errors.New("two-factor authentication is already enabled"), http.StatusConflict)
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// The secret stays pending until the user confirms it with a valid code
+	user.TwoFactorSecret = secret
+	user.TwoFactorConfirmedAt = nil
+	user.TwoFactorLastCounter = 0
+	if err := c.DB.Save(&user).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	appConfig := config.LoadAppConfig()
+	utils.JSONResponse(w, http.StatusOK, responses.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(appConfig.Name, user.Email, secret),
+		Message:         "scan the provisioning uri and confirm with a code to enable two-factor authentication",
+	})
+}
+
+// Confirm handles the POST /user/confirmed-two-factor-authentication request to finish a two-factor enrollment.
+//
+// The user proves that the authenticator app was set up correctly by sending the first code.
+// On success two-factor authentication is enabled and a set of single-use recovery codes is returned.
+//
+//	@Summary		Confirm two-factor authentication
+//	@Description	Confirms a pending two-factor enrollment and returns recovery codes
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			confirmRequest	body		requests.ConfirmTwoFactorRequest	true	"First TOTP code"
+//	@Success		200				{object}	responses.TwoFactorRecoveryCodesResponse
+//	@Failure		400				{object}	utils.SwaggerErrorResponse
+//	@Failure		401				{object}	utils.SwaggerErrorResponse
+//	@Failure		422				{object}	utils.SwaggerErrorResponse
+//	@Failure		500				{object}	utils.SwaggerErrorResponse
+//	@Router			/user/confirmed-two-factor-authentication [post]
+//	@Security		BearerAuth
+func (c TwoFactorController) Confirm(w http.ResponseWriter, r *http.Request) {
+	var confirmReq requests.ConfirmTwoFactorRequest
+	if err := utils.DecodeJSONBody(w, r, &confirmReq); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &confirmReq); err != nil {
+		return
+	}
+
+	userID, err := authn.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var user Models.User
+	if err := c.DB.First(&user, userID).Error; err != nil {
+		utils.HandleError(w, errors.New("user not found"), http.StatusNotFound)
+		return
+	}
+
+	if user.TwoFactorSecret == "" {
+		utils.HandleError(w, errors.New("two-factor authentication has not been enabled"), http.StatusUnprocessableEntity)
+		return
+	}
+	if user.HasTwoFactorEnabled() {
+		utils.HandleError(w, errors.New("two-factor authentication is already confirmed"), http.StatusConflict)
+		return
+	}
+
+	if err := services.VerifyTwoFactorCode(c.DB, &user, confirmReq.Code); err != nil {
+		utils.HandleError(w, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	now := time.Now()
+	if err := c.DB.Model(&user).Update("two_factor_confirmed_at", &now).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	codes, err := services.GenerateRecoveryCodes(c.DB, user.ID)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, responses.TwoFactorRecoveryCodesResponse{
+		RecoveryCodes: codes,
+		Message:       "two-factor authentication enabled, store the recovery codes somewhere safe",
+	})
+}
+
+// RecoveryCodes handles the POST /user/two-factor-recovery-codes request to regenerate recovery codes.
+//
+// The previous recovery codes stop working. The user has to re-enter their password.
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replaces the two-factor recovery codes of the authenticated user
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			passwordRequest	body		requests.DisableTwoFactorRequest	true	"Current password"
+//	@Success		200				{object}	responses.TwoFactorRecoveryCodesResponse
+//	@Failure		400				{object}	utils.SwaggerErrorResponse
+//	@Failure		401				{object}	utils.SwaggerErrorResponse
+//	@Failure		422				{object}	utils.SwaggerErrorResponse
+//	@Failure		500				{object}	utils.SwaggerErrorResponse
+//	@Router			/user/two-factor-recovery-codes [post]
+//	@Security		BearerAuth
+func (c TwoFactorController) RecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var passwordReq requests.DisableTwoFactorRequest
+	if err := utils.DecodeJSONBody(w, r, &passwordReq); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &passwordReq); err != nil {
+		return
+	}
+
+	userID, err := authn.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var user Models.User
+	if err := c.DB.First(&user, userID).Error; err != nil {
+		utils.HandleError(w, errors.New("user not found"), http.StatusNotFound)
+		return
+	}
+
+	if err := utils.VerifyPassword(user.Password, passwordReq.Password); err != nil {
+		utils.HandleError(w, errors.New("the password is incorrect"), http.StatusUnprocessableEntity)
+		return
+	}
+	if !user.HasTwoFactorEnabled() {
+		utils.HandleError(w, errors.New("two-factor authentication is not enabled"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	codes, err := services.GenerateRecoveryCodes(c.DB, user.ID)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, responses.TwoFactorRecoveryCodesResponse{
+		RecoveryCodes: codes,
+		Message:       "recovery codes regenerated",
+	})
+}
+
+// Delete handles the DELETE /user/two-factor-authentication request to disable two-factor authentication.
+//
+// The user has to re-enter their password. The TOTP secret and all recovery codes are removed.
+//
+//	@Summary		Disable two-factor authentication
+//	@Description	Disables two-factor authentication after password re-entry
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			disableRequest	body		requests.DisableTwoFactorRequest	true	"Current password"
+//	@Success		200				{object}	utils.SwaggerSuccessResponse
+//	@Failure		400				{object}	utils.SwaggerErrorResponse
+//	@Failure		401				{object}	utils.SwaggerErrorResponse
+//	@Failure		422				{object}	utils.SwaggerErrorResponse
+//	@Failure		500				{object}	utils.SwaggerErrorResponse
+//	@Router			/user/two-factor-authentication [delete]
+//	@Security		BearerAuth
+func (c TwoFactorController) Delete(w http.ResponseWriter, r *http.Request) {
+	var disableReq requests.DisableTwoFactorRequest
+	if err := utils.DecodeJSONBody(w, r, &disableReq); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &disableReq); err != nil {
+		return
+	}
+
+	userID, err := authn.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var user Models.User
+	if err := c.DB.First(&user, userID).Error; err != nil {
+		utils.HandleError(w, errors.New("user not found"), http.StatusNotFound)
+		return
+	}
+
+	if err := utils.VerifyPassword(user.Password, disableReq.Password); err != nil {
+		utils.HandleError(w, errors.New("the password is incorrect"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_secret":       "",
+			"two_factor_confirmed_at": nil,
+			"two_factor_last_counter": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&Models.TwoFactorRecoveryCode{}).Error
+	})
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "two-factor authentication disabled",
+	})
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"errors"
+	"gonga/app/Models"
+	services "gonga/app/Services"
+	auth "gonga/packages/Auth"
+	"gonga/utils"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// BlockController manages the users the authenticated user blocked.
+type BlockController struct {
+	DB *gorm.DB
+}
+
+// Index handles the GET /me/blocks request to list the users the authenticated user blocked.
+//
+//	@Summary		List blocked users
+//	@Description	Lists the users the authenticated user blocked
+//	@Tags			Blocks
+//	@Produce		json
+//	@Param			page		query		int	false	"Page number for pagination"
+//	@Param			per_page	query		int	false	"Number of items per page"
+//	@Success		200			{object}	utils.SwaggerPagination
+//	@Failure		401			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/me/blocks [get]
+//	@Security		BearerAuth
+func (c BlockController) Index(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var blocks []Models.Block
+	var response utils.APIResponse
+
+	query := c.DB.Where("blocker_id = ?", userID)
+	paginationScope, err := utils.Paginate(r, query, &blocks, &response, "Blocked")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if err := paginationScope(query).Find(&blocks).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.Data = blocks
+	response.Type = "success"
+	response.Message = "data retrieved successfully"
+
+	utils.JSONResponse(w, http.StatusOK, response)
+}
+
+// Create handles the POST /users/{username}/block request to block a user.
+//
+// The two users stop seeing each other's posts, comments and profile, and cannot mention each other or
+// comment on each other's posts. Follows between them are removed.
+//
+//	@Summary		Block a user
+//	@Description	Blocks a user and removes the follows between the two users
+//	@Tags			Blocks
+//	@Produce		json
+//	@Param			username	path		string	true	"Username of the user to block"
+//	@Success		200			{object}	utils.SwaggerSuccessResponse
+//	@Failure		400			{object}	utils.SwaggerErrorResponse
+//	@Failure		401			{object}	utils.SwaggerErrorResponse
+//	@Failure		404			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/users/{username}/block [post]
+//	@Security		BearerAuth
+func (c BlockController) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	target, status, err := findUserByUsername(c.DB, r)
+	if err != nil {
+		utils.HandleError(w, err, status)
+		return
+	}
+
+	if err := services.BlockUser(c.DB, userID, target.ID); err != nil {
+		if errors.Is(err, services.ErrCannotBlockSelf) {
+			utils.HandleError(w, err, http.StatusBadRequest)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "user blocked",
+	})
+}
+
+// Delete handles the DELETE /users/{username}/block request to unblock a user.
+//
+//	@Summary		Unblock a user
+//	@Description	Removes the block of a user
+//	@Tags			Blocks
+//	@Produce		json
+//	@Param			username	path		string	true	"Username of the user to unblock"
+//	@Success		200			{object}	utils.SwaggerSuccessResponse
+//	@Failure		401			{object}	utils.SwaggerErrorResponse
+//	@Failure		404			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/users/{username}/block [delete]
+//	@Security		BearerAuth
+func (c BlockController) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	target, status, err := findUserByUsername(c.DB, r)
+	if err != nil {
+		utils.HandleError(w, err, status)
+		return
+	}
+
+	if err := services.UnblockUser(c.DB, userID, target.ID); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "user unblocked",
+	})
+}
+
+// findUserByUsername returns the user of the {username} route parameter, or the error and status to respond with.
+func findUserByUsername(db *gorm.DB, r *http.Request) (*Models.User, int, error) {
+	username, err := utils.GetParam(r, "username")
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	var user Models.User
+	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, errors.New("user not found")
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return &user, http.StatusOK, nil
+}
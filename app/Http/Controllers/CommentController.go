@@ -4,9 +4,12 @@ import (
 	"errors"
 	requests "gonga/app/Http/Requests"
 	"gonga/app/Models"
+	policies "gonga/app/Policies"
 	services "gonga/app/Services"
 
 	// services "gonga/app/Services"
+	auth "gonga/packages/Auth"
+	contentfilter "gonga/packages/ContentFilter"
 	"gonga/utils"
 	"log"
 	"net/http"
@@ -43,18 +46,30 @@ func (c CommentController) Index(w http.ResponseWriter, r *http.Request) {
 	var comments []Models.Comment
 	var response utils.APIResponse
 
-	// Apply the where condition to filter comments by postID and parentID
-	db := c.DB.Where("post_id = ? AND parent_id IS NULL", postID)
+	// Apply the where condition to filter comments by postID and parentID, leaving out the comments
+	// of banned users, of users the caller blocked, muted or was blocked by, and the comments that
+	// contain words the caller muted
+	viewerID, _ := auth.ID(r)
+	hideMutedWords, err := services.HideMutedWords(c.DB, viewerID, "body")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	hidden := func(db *gorm.DB) *gorm.DB {
+		return db.Where("moderation_status = ?", Models.ModerationVisible).
+			Scopes(services.HideBanned("user_id"), services.HideBlockedAndMuted(viewerID, "user_id"), hideMutedWords)
+	}
+	db := c.DB.Where("post_id = ? AND parent_id IS NULL", postID).Scopes(hidden)
 
 	// Apply pagination and retrieve paginated comments
-	paginationScope, err := utils.Paginate(r, db, &comments, &response, "User", "Mentions.User", "Childrens", "Likes")
+	paginationScope, err := utils.Paginate(r, db, &comments, &response, "User", "Mentions.User", "Likes")
 	if err != nil {
 		utils.HandleError(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	// Apply the pagination scope to the filtered query
-	db = paginationScope(db)
+	db = paginationScope(db).Preload("Childrens", hidden).Preload("Childrens.User")
 
 	// Retrieve the paginated comments
 	if err := db.Find(&comments).Error; err != nil {
@@ -83,6 +98,7 @@ func (c CommentController) Index(w http.ResponseWriter, r *http.Request) {
 //	@Success		200	{object}	utils.SwaggerSuccessResponse
 //	@Failure		400	{object}	utils.SwaggerErrorResponse
 //	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		410	{object}	utils.Tombstone
 //	@Failure		500	{object}	utils.SwaggerErrorResponse
 //	@Router			/comments/{id} [get]
 func (c CommentController) Show(w http.ResponseWriter, r *http.Request) {
@@ -95,8 +111,20 @@ func (c CommentController) Show(w http.ResponseWriter, r *http.Request) {
 	// Create a variable to hold the comment
 	var comment Models.Comment
 
-	// Retrieve the comment with the specified ID from the database
-	if err := c.DB.Preload("Childrens").First(&comment, commentID).Error; err != nil {
+	// Retrieve the comment with the specified ID from the database, without the replies the caller
+	// shouldn't see
+	viewerID, _ := auth.ID(r)
+	hideMutedWords, err := services.HideMutedWords(c.DB, viewerID, "body")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	hidden := func(db *gorm.DB) *gorm.DB {
+		return db.Where("moderation_status = ?", Models.ModerationVisible).
+			Scopes(services.HideBanned("user_id"), services.HideBlockedAndMuted(viewerID, "user_id"), hideMutedWords)
+	}
+	if err := c.DB.Preload("User").Preload("Mentions.User").Preload("Childrens", hidden).Preload("Childrens.User").
+		First(&comment, commentID).Error; err != nil {
 		// If the comment is not found, return a not found response
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.HandleError(w, err, http.StatusNotFound, "comment not found")
@@ -108,6 +136,21 @@ func (c CommentController) Show(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Comments of blocked and banned users are shown as not found, so the block is not revealed
+	if blocked, err := services.IsBlocked(c.DB, viewerID, comment.AuthorID()); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	} else if blocked || (comment.User != nil && comment.User.IsBanned()) {
+		utils.HandleError(w, errors.New("comment not found"), http.StatusNotFound)
+		return
+	}
+	// Comments removed or hidden by moderators leave a tombstone. Authors still see their hidden
+	// comments, with their moderation status, a comment held by the content filter isn't removed yet.
+	if comment.ModerationStatus != Models.ModerationVisible && !(comment.ModerationStatus == Models.ModerationHidden && viewerID != 0 && viewerID == comment.AuthorID()) {
+		writeTombstone(w, "comments", comment.ID, comment.ModerationStatus, comment.UpdatedAt)
+		return
+	}
+
 	// Send the comment as a response
 	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
 		Data:    comment,
@@ -131,6 +174,9 @@ func (c CommentController) Show(w http.ResponseWriter, r *http.Request) {
 //	@Success		200		{object}	utils.SwaggerSuccessResponse
 //	@Failure		400		{object}	utils.SwaggerErrorResponse
 //	@Failure		401		{object}	utils.SwaggerErrorResponse
+//	@Failure		403		{object}	utils.SwaggerErrorResponse
+//	@Failure		404		{object}	utils.SwaggerErrorResponse
+//	@Failure		422		{object}	utils.SwaggerErrorResponse
 //	@Failure		500		{object}	utils.SwaggerErrorResponse
 //	@Router			/posts/{id}/comments [post]
 func (c CommentController) Create(w http.ResponseWriter, r *http.Request) {
@@ -158,9 +204,9 @@ func (c CommentController) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, err := utils.GetUserIDFromContext(r.Context())
+	userID, err := auth.ID(r)
 	if err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+		utils.HandleError(w, err, http.StatusUnauthorized)
 		return
 	}
 
@@ -170,6 +216,19 @@ func (c CommentController) Create(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, errors.New("invalid post ID"), http.StatusBadRequest)
 		return
 	}
+	// Users cannot comment on posts of users who blocked them or whom they blocked
+	var post Models.Post
+	if err := c.DB.First(&post, postID).Error; err != nil {
+		utils.HandleError(w, errors.New("post not found"), http.StatusNotFound)
+		return
+	}
+	if blocked, err := services.IsBlocked(c.DB, userID, post.UserID); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	} else if blocked {
+		utils.HandleError(w, services.ErrBlocked, http.StatusForbidden)
+		return
+	}
 	// Check if the parent comment exists
 	if createReq.ParentID != nil {
 		var parentComment Models.Comment
@@ -177,14 +236,31 @@ func (c CommentController) Create(w http.ResponseWriter, r *http.Request) {
 			utils.HandleError(w, errors.New("invalid parent comment ID"), http.StatusBadRequest)
 			return
 		}
+		if blocked, err := services.IsBlocked(c.DB, userID, parentComment.AuthorID()); err != nil {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+			return
+		} else if blocked {
+			utils.HandleError(w, services.ErrBlocked, http.StatusForbidden)
+			return
+		}
+	}
+	// Run the comment through the content filter
+	content := contentfilter.Content{Type: "comments", AuthorID: userID, Body: createReq.Body}
+	filterResult, ok := filterContent(w, r, c.DB, content)
+	if !ok {
+		return
 	}
+
 	// Create a new Comment instance
 	newComment := Models.Comment{
-		UserID:   uint(userID.(float64)),
+		UserID:   &userID,
 		PostID:   uint(postID),
 		Body:     createReq.Body,
 		ParentID: createReq.ParentID,
 	}
+	if filterResult.Action == contentfilter.Hold {
+		newComment.ModerationStatus = Models.ModerationHidden
+	}
 
 	// Insert the comment into the database
 	if err := c.DB.Create(&newComment).Error; err != nil {
@@ -192,9 +268,18 @@ func (c CommentController) Create(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, errors.New("failed to create comment"), http.StatusInternalServerError)
 		return
 	}
+	content.ID = newComment.ID
+	applyContentResult(c.DB, content, filterResult)
+
+	// Users who blocked the author or were blocked by them are not mentioned
+	mentions, err := services.WithoutBlockedMentions(c.DB, userID, createReq.Mentions)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
 
 	// Create mentions for the comment
-	for _, mentionedUser := range createReq.Mentions {
+	for _, mentionedUser := range mentions {
 		log.Println(mentionedUser.UserID)
 		mention := &Models.Mention{
 			UserID:    mentionedUser.UserID,
@@ -209,9 +294,13 @@ func (c CommentController) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Send the created comment as a response
+	message := "comment created successfully!"
+	if filterResult.Action == contentfilter.Hold {
+		message = "comment created, it will be published after a review"
+	}
 	utils.JSONResponse(w, http.StatusOK, &utils.APIResponse{
 		Type:    "success",
-		Message: "comment created successfully!",
+		Message: message,
 	})
 }
 
@@ -230,7 +319,9 @@ func (c CommentController) Create(w http.ResponseWriter, r *http.Request) {
 //	@Success		200				{object}	utils.SwaggerSuccessResponse
 //	@Failure		400				{object}	utils.SwaggerErrorResponse
 //	@Failure		401				{object}	utils.SwaggerErrorResponse
+//	@Failure		403				{object}	utils.SwaggerErrorResponse
 //	@Failure		404				{object}	utils.SwaggerErrorResponse
+//	@Failure		422				{object}	utils.SwaggerErrorResponse
 //	@Failure		500				{object}	utils.SwaggerErrorResponse
 //	@Router			/comments/{id} [put]
 func (c CommentController) Update(w http.ResponseWriter, r *http.Request) {
@@ -259,15 +350,15 @@ func (c CommentController) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, err := utils.GetUserIDFromContext(r.Context())
+	user, err := auth.User(r)
 	if err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+		utils.HandleError(w, err, http.StatusUnauthorized)
 		return
 	}
 
-	// Check if the comment exists and belongs to the authenticated user
+	// Check if the comment exists and the authenticated user may edit it
 	var comment Models.Comment
-	if err := c.DB.Where("id = ? AND user_id = ?", commentID, userID).First(&comment).Error; err != nil {
+	if err := c.DB.First(&comment, commentID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			utils.HandleError(w, errors.New("comment not found"), http.StatusNotFound)
 		} else {
@@ -275,6 +366,17 @@ func (c CommentController) Update(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	if !policies.Can(user, policies.Update, &comment) {
+		utils.HandleError(w, errors.New("you are not authorized to update this comment"), http.StatusForbidden)
+		return
+	}
+
+	// Run the new body through the content filter
+	content := contentfilter.Content{Type: "comments", ID: comment.ID, AuthorID: comment.AuthorID(), Body: updateReq.Body}
+	filterResult, ok := filterContent(w, r, c.DB, content)
+	if !ok {
+		return
+	}
 
 	// Update the comment body
 	comment.Body = updateReq.Body
@@ -284,9 +386,15 @@ func (c CommentController) Update(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, errors.New("failed to update comment"), http.StatusInternalServerError)
 		return
 	}
+	applyContentResult(c.DB, content, filterResult)
 
 	// Perform the edit mentions operation
-	err = services.EditMentions(c.DB, comment.ID, "comments", updateReq.Mentions)
+	mentions, err := services.WithoutBlockedMentions(c.DB, comment.AuthorID(), updateReq.Mentions)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	err = services.EditMentions(c.DB, comment.ID, "comments", mentions)
 	if err != nil {
 		utils.HandleError(w, err, http.StatusInternalServerError)
 		return
@@ -314,6 +422,7 @@ func (c CommentController) Update(w http.ResponseWriter, r *http.Request) {
 //	@Success		200				{object}	utils.SwaggerSuccessResponse
 //	@Failure		400				{object}	utils.SwaggerErrorResponse
 //	@Failure		401				{object}	utils.SwaggerErrorResponse
+//	@Failure		403				{object}	utils.SwaggerErrorResponse
 //	@Failure		404				{object}	utils.SwaggerErrorResponse
 //	@Failure		500				{object}	utils.SwaggerErrorResponse
 //	@Router			/comments/{id} [delete]
@@ -325,12 +434,22 @@ func (c CommentController) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user, err := auth.User(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
 	// Check if the comment exists
 	var comment Models.Comment
 	if err := c.DB.First(&comment, commentID).Error; err != nil {
 		utils.HandleError(w, errors.New("comment not found"), http.StatusNotFound)
 		return
 	}
+	if !policies.Can(user, policies.Delete, &comment) {
+		utils.HandleError(w, errors.New("you are not authorized to delete this comment"), http.StatusForbidden)
+		return
+	}
 
 	// Delete the comment
 	if err := c.DB.Delete(&comment).Error; err != nil {
@@ -4,6 +4,8 @@ import (
 	"errors"
 	requests "gonga/app/Http/Requests"
 	"gonga/app/Models"
+	policies "gonga/app/Policies"
+	auth "gonga/packages/Auth"
 	"gonga/utils"
 	"log"
 	"net/http"
@@ -15,14 +17,6 @@ type LikeController struct {
 	DB *gorm.DB
 }
 
-func (c LikeController) Index(w http.ResponseWriter, r *http.Request) {
-	// Handle GET /likecontroller request
-}
-
-func (c LikeController) Show(w http.ResponseWriter, r *http.Request) {
-	// Handle GET /likecontroller/{id} request
-}
-
 // Create handles the POST /likes request to create a new like.
 //
 // This endpoint allows users to create a new like for a specific likeable item.
@@ -39,10 +33,10 @@ func (c LikeController) Show(w http.ResponseWriter, r *http.Request) {
 //	@Failure		500		{object}	utils.SwaggerErrorResponse
 //	@Router			/likes [post]
 func (c LikeController) Create(w http.ResponseWriter, r *http.Request) {
-	userID, err := utils.GetUserIDFromContext(r.Context())
+	userID, err := auth.ID(r)
 
 	if err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+		utils.HandleError(w, err, http.StatusUnauthorized)
 		return
 	}
 	// Parse like data from request body
@@ -84,7 +78,7 @@ func (c LikeController) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	like := &Models.Like{
-		UserID:       uint(userID.(float64)),
+		UserID:       userID,
 		LikeableID:   createReq.LikeableID,
 		LikeableType: createReq.LikeableType,
 	}
@@ -100,12 +94,6 @@ func (c LikeController) Create(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (c LikeController) Update(w http.ResponseWriter, r *http.Request) {
-	// Handle PUT /likecontroller/{id} request
-	// You can get the request body by reading from r.Body
-	// You can send a response by writing to w
-}
-
 // Delete handles the DELETE /likes/{id} request to delete a like.
 //
 // This endpoint allows users to delete a like based on its ID.
@@ -117,6 +105,7 @@ func (c LikeController) Update(w http.ResponseWriter, r *http.Request) {
 //	@Success		200	{object}	utils.SwaggerSuccessResponse
 //	@Failure		400	{object}	utils.SwaggerErrorResponse
 //	@Failure		401	{object}	utils.SwaggerErrorResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
 //	@Failure		404	{object}	utils.SwaggerErrorResponse
 //	@Failure		500	{object}	utils.SwaggerErrorResponse
 //	@Router			/likes/{id} [delete]
@@ -126,10 +115,10 @@ func (c LikeController) Delete(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, err, http.StatusBadRequest)
 		return
 	}
-	userID, err := utils.GetUserIDFromContext(r.Context()) // Get the user ID from the context
+	user, err := auth.User(r) // Get the authenticated user
 
 	if err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+		utils.HandleError(w, err, http.StatusUnauthorized)
 		return
 	}
 
@@ -141,8 +130,8 @@ func (c LikeController) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if the current user is the owner of the like
-	if like.UserID != uint(userID.(float64)) {
-		utils.HandleError(w, errors.New("Unauthorized"), http.StatusUnauthorized)
+	if !policies.Can(user, policies.Delete, &like) {
+		utils.HandleError(w, errors.New("you are not authorized to delete this like"), http.StatusForbidden)
 		return
 	}
 
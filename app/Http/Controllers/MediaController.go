@@ -3,6 +3,7 @@ package controllers
 import (
 	responses "gonga/app/Http/Responses"
 	"gonga/app/Models"
+	auth "gonga/packages/Auth"
 	cloudinary "gonga/packages/Cloudinary"
 	"gonga/utils"
 	"net/http"
@@ -45,6 +46,12 @@ func (c MediaController) Upload(w http.ResponseWriter, r *http.Request) {
 	}
 	ownerID := r.FormValue("owner_id") // Get the owner ID from the request form data
 
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
 	cloudinaryClient := cloudinary.NewCloudinaryClient()
 
 	for _, fileHeader := range files {
@@ -67,6 +74,8 @@ func (c MediaController) Upload(w http.ResponseWriter, r *http.Request) {
 		media := Models.Media{
 			URL:       result.URL,
 			Type:      result.Type,           // Set the appropriate media type
+			PublicID:  publicID,              // Needed to delete the file from Cloudinary later
+			UserID:    userID,
 			OwnerType: ownerType,             // Set the owner type dynamically or fallback to "post"
 			OwnerID:   parseOwnerID(ownerID), // Parse the owner ID based on its type (post, comment, etc.)
 		}
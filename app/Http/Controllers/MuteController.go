@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"errors"
+	"gonga/app/Models"
+	services "gonga/app/Services"
+	auth "gonga/packages/Auth"
+	"gonga/utils"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// MuteController manages the users the authenticated user muted.
+type MuteController struct {
+	DB *gorm.DB
+}
+
+// Index handles the GET /me/mutes request to list the users the authenticated user muted.
+//
+//	@Summary		List muted users
+//	@Description	Lists the users the authenticated user muted
+//	@Tags			Mutes
+//	@Produce		json
+//	@Param			page		query		int	false	"Page number for pagination"
+//	@Param			per_page	query		int	false	"Number of items per page"
+//	@Success		200			{object}	utils.SwaggerPagination
+//	@Failure		401			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/me/mutes [get]
+//	@Security		BearerAuth
+func (c MuteController) Index(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var mutes []Models.Mute
+	var response utils.APIResponse
+
+	query := c.DB.Where("muter_id = ?", userID)
+	paginationScope, err := utils.Paginate(r, query, &mutes, &response, "Muted")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if err := paginationScope(query).Find(&mutes).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.Data = mutes
+	response.Type = "success"
+	response.Message = "data retrieved successfully"
+
+	utils.JSONResponse(w, http.StatusOK, response)
+}
+
+// Create handles the POST /users/{username}/mute request to mute a user.
+//
+// The muted user's posts and comments are hidden from the authenticated user only. The muted user is not told.
+//
+//	@Summary		Mute a user
+//	@Description	Hides a user's posts and comments from the authenticated user
+//	@Tags			Mutes
+//	@Produce		json
+//	@Param			username	path		string	true	"Username of the user to mute"
+//	@Success		200			{object}	utils.SwaggerSuccessResponse
+//	@Failure		400			{object}	utils.SwaggerErrorResponse
+//	@Failure		401			{object}	utils.SwaggerErrorResponse
+//	@Failure		404			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/users/{username}/mute [post]
+//	@Security		BearerAuth
+func (c MuteController) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	target, status, err := findUserByUsername(c.DB, r)
+	if err != nil {
+		utils.HandleError(w, err, status)
+		return
+	}
+
+	if err := services.MuteUser(c.DB, userID, target.ID); err != nil {
+		if errors.Is(err, services.ErrCannotBlockSelf) {
+			utils.HandleError(w, err, http.StatusBadRequest)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "user muted",
+	})
+}
+
+// Delete handles the DELETE /users/{username}/mute request to unmute a user.
+//
+//	@Summary		Unmute a user
+//	@Description	Removes the mute of a user
+//	@Tags			Mutes
+//	@Produce		json
+//	@Param			username	path		string	true	"Username of the user to unmute"
+//	@Success		200			{object}	utils.SwaggerSuccessResponse
+//	@Failure		401			{object}	utils.SwaggerErrorResponse
+//	@Failure		404			{object}	utils.SwaggerErrorResponse
+//	@Failure		500			{object}	utils.SwaggerErrorResponse
+//	@Router			/users/{username}/mute [delete]
+//	@Security		BearerAuth
+func (c MuteController) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	target, status, err := findUserByUsername(c.DB, r)
+	if err != nil {
+		utils.HandleError(w, err, status)
+		return
+	}
+
+	if err := services.UnmuteUser(c.DB, userID, target.ID); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "user unmuted",
+	})
+}
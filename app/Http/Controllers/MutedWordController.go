@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"errors"
+	requests "gonga/app/Http/Requests"
+	"gonga/app/Models"
+	services "gonga/app/Services"
+	auth "gonga/packages/Auth"
+	"gonga/utils"
+	"log"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// MutedWordController manages the words the authenticated user muted. Posts and comments containing
+// a muted word are left out of the user's feeds and comment threads.
+type MutedWordController struct {
+	DB *gorm.DB
+}
+
+// Index handles the GET /me/muted-words request to list the words the authenticated user muted.
+//
+//	@Summary		List muted words
+//	@Description	Lists the words and phrases the authenticated user muted
+//	@Tags			Mutes
+//	@Produce		json
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		401	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/me/muted-words [get]
+//	@Security		BearerAuth
+func (c MutedWordController) Index(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var words []Models.MutedWord
+	if err := c.DB.Where("user_id = ?", userID).Order("word").Find(&words).Error; err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type: "success",
+		Data: words,
+	})
+}
+
+// Create handles the POST /me/muted-words request to mute a word or phrase.
+//
+// Muted words also match when they are disguised with look-alike characters or punctuation.
+//
+//	@Summary		Mute a word
+//	@Description	Hides posts and comments containing the word or phrase from the authenticated user
+//	@Tags			Mutes
+//	@Accept			json
+//	@Produce		json
+//	@Param			createMutedWordRequest	body		requests.CreateMutedWordRequest	true	"Word or phrase"
+//	@Success		201						{object}	utils.SwaggerSuccessResponse
+//	@Failure		400						{object}	utils.SwaggerErrorResponse
+//	@Failure		401						{object}	utils.SwaggerErrorResponse
+//	@Failure		409						{object}	utils.SwaggerErrorResponse
+//	@Failure		500						{object}	utils.SwaggerErrorResponse
+//	@Router			/me/muted-words [post]
+//	@Security		BearerAuth
+func (c MutedWordController) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var req requests.CreateMutedWordRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	word, err := services.AddMutedWord(c.DB, userID, req.Word)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMutedWordLimit):
+			utils.HandleError(w, err, http.StatusBadRequest)
+		case errors.Is(err, services.ErrMutedWordTaken):
+			utils.HandleError(w, err, http.StatusConflict)
+		default:
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, utils.APIResponse{
+		Type:    "success",
+		Message: "word muted",
+		Data:    word,
+	})
+}
+
+// Delete handles the DELETE /me/muted-words/{id} request to unmute a word.
+//
+//	@Summary		Unmute a word
+//	@Description	Removes a muted word of the authenticated user
+//	@Tags			Mutes
+//	@Produce		json
+//	@Param			id	path		int	true	"Muted word ID"
+//	@Success		200	{object}	utils.SwaggerSuccessResponse
+//	@Failure		401	{object}	utils.SwaggerErrorResponse
+//	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		500	{object}	utils.SwaggerErrorResponse
+//	@Router			/me/muted-words/{id} [delete]
+//	@Security		BearerAuth
+func (c MutedWordController) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	id, err := utils.GetParam(r, "id")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	result := c.DB.Unscoped().Where("id = ? AND user_id = ?", id, userID).Delete(&Models.MutedWord{})
+	if result.Error != nil {
+		utils.HandleError(w, result.Error, http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.HandleError(w, errors.New("muted word not found"), http.StatusNotFound)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
+		Type:    "success",
+		Message: "word unmuted",
+	})
+}
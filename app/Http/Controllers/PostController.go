@@ -2,12 +2,18 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
 	requests "gonga/app/Http/Requests"
 	"gonga/app/Models"
+	policies "gonga/app/Policies"
 	services "gonga/app/Services"
+	auth "gonga/packages/Auth"
+	contentfilter "gonga/packages/ContentFilter"
 	"gonga/utils"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -35,13 +41,24 @@ func (c PostController) Index(w http.ResponseWriter, r *http.Request) {
 	var posts []Models.Post
 	var response utils.APIResponse
 
-	paginationScope, err := utils.Paginate(r, c.DB, &posts, &response, "User", "Medias", "Mentions.User", "Hashtags")
+	// Leave out the posts of banned users, of users the caller blocked, muted or was blocked by, and
+	// the posts that contain words the caller muted
+	viewerID, _ := auth.ID(r)
+	hideMutedWords, err := services.HideMutedWords(c.DB, viewerID, "posts.title", "posts.body")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	db := c.DB.Where("posts.moderation_status = ?", Models.ModerationVisible).
+		Scopes(services.HideBanned("posts.user_id"), services.HideBlockedAndMuted(viewerID, "posts.user_id"), hideMutedWords)
+
+	paginationScope, err := utils.Paginate(r, db, &posts, &response, "User", "Medias", "Mentions.User", "Hashtags")
 	if err != nil {
 		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to paginate posts")
 		return
 	}
 
-	db := paginationScope(c.DB)
+	db = paginationScope(db)
 	if err := db.Find(&posts).Error; err != nil {
 		utils.HandleError(w, err, http.StatusInternalServerError, "Failed to retrieve posts")
 		return
@@ -67,6 +84,7 @@ func (c PostController) Index(w http.ResponseWriter, r *http.Request) {
 //	@Failure		400	{object}	utils.SwaggerErrorResponse
 //	@Failure		401	{object}	utils.SwaggerErrorResponse
 //	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		410	{object}	utils.Tombstone
 //	@Router			/posts/{id} [get]
 func (c PostController) Show(w http.ResponseWriter, r *http.Request) {
 	// Handle GET /postcontroller/{id} request
@@ -93,6 +111,21 @@ func (c PostController) Show(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, err, http.StatusNotFound)
 		return
 	}
+	// Posts of blocked users are shown as not found, so the block is not revealed
+	viewerID, _ := auth.ID(r)
+	if blocked, err := services.IsBlocked(c.DB, viewerID, post.UserID); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	} else if blocked || (post.User != nil && post.User.IsBanned()) {
+		utils.HandleError(w, errors.New("record not found"), http.StatusNotFound)
+		return
+	}
+	// Posts removed or hidden by moderators leave a tombstone. Authors still see their hidden posts,
+	// with their moderation status, a post held by the content filter isn't removed yet.
+	if post.ModerationStatus != Models.ModerationVisible && !(post.ModerationStatus == Models.ModerationHidden && viewerID != 0 && viewerID == post.UserID) {
+		writeTombstone(w, "posts", post.ID, post.ModerationStatus, post.UpdatedAt)
+		return
+	}
 	// Return successful response with the post data
 	response := utils.APIResponse{
 		Type: "success",
@@ -116,6 +149,7 @@ func (c PostController) Show(w http.ResponseWriter, r *http.Request) {
 //	@Success		201				{object}	utils.SwaggerSuccessResponse
 //	@Failure		400				{object}	utils.SwaggerErrorResponse
 //	@Failure		401				{object}	utils.SwaggerErrorResponse
+//	@Failure		422				{object}	utils.SwaggerErrorResponse
 //	@Failure		500				{object}	utils.SwaggerErrorResponse
 //	@Router			/posts [post]
 func (c PostController) Create(w http.ResponseWriter, r *http.Request) {
@@ -137,11 +171,18 @@ func (c PostController) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, err := utils.GetUserIDFromContext(r.Context())
+	userID, err := auth.ID(r)
 	if err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+		utils.HandleError(w, err, http.StatusUnauthorized)
 		return
 	}
+	// Run the post through the content filter
+	content := contentfilter.Content{Type: "posts", AuthorID: userID, Title: createReq.Title, Body: createReq.Body}
+	filterResult, ok := filterContent(w, r, c.DB, content)
+	if !ok {
+		return
+	}
+
 	newPost := Models.Post{
 		Title:           createReq.Title,
 		Body:            createReq.Body,
@@ -150,7 +191,10 @@ func (c PostController) Create(w http.ResponseWriter, r *http.Request) {
 		Visibility:      createReq.Visibility,
 		PromotionExpiry: createReq.PromotionExpiry,
 		FeaturedExpiry:  createReq.FeaturedExpiry,
-		UserID:          uint(userID.(float64)),
+		UserID:          userID,
+	}
+	if filterResult.Action == contentfilter.Hold {
+		newPost.ModerationStatus = Models.ModerationHidden
 	}
 	// Insert the post in the database
 	result := c.DB.Create(&newPost)
@@ -159,6 +203,8 @@ func (c PostController) Create(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, result.Error, http.StatusInternalServerError, "failed to create post in the database")
 		return
 	}
+	content.ID = newPost.ID
+	applyContentResult(c.DB, content, filterResult)
 
 	// Associate the media files with the post
 	for _, newMedia := range createReq.Medias {
@@ -175,8 +221,15 @@ func (c PostController) Create(w http.ResponseWriter, r *http.Request) {
 		c.DB.Save(&media)
 	}
 
+	// Users who blocked the author or were blocked by them are not mentioned
+	mentions, err := services.WithoutBlockedMentions(c.DB, userID, createReq.Mentions)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
 	// Iterate over the mention user IDs
-	for _, mentionedUser := range createReq.Mentions {
+	for _, mentionedUser := range mentions {
 		log.Println(mentionedUser.UserID)
 		mention := &Models.Mention{
 			UserID:    mentionedUser.UserID,
@@ -203,7 +256,7 @@ func (c PostController) Create(w http.ResponseWriter, r *http.Request) {
 				// Create a new tag since it doesn't exist
 				tag = Models.Tag{
 					Title:  hashtag.Title,
-					UserID: uint(userID.(float64)),
+					UserID: &userID,
 					// Set other tag fields as needed
 				}
 				if err := c.DB.Create(&tag).Error; err != nil {
@@ -231,48 +284,16 @@ func (c PostController) Create(w http.ResponseWriter, r *http.Request) {
 	// 	utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	// 	return
 	// }
+	message := "The post was created successfully"
+	if filterResult.Action == contentfilter.Hold {
+		message = "The post was created and will be published after a review"
+	}
 	utils.JSONResponse(w, http.StatusCreated, &utils.APIResponse{
 		Type:    "success",
-		Message: "The post was created successfully",
+		Message: message,
 	})
 }
 
-// Update handles the PUT /posts/{id} request to update a specific post.
-//
-// This endpoint allows users to update the details of a specific post identified by its ID.
-//
-//	@Summary		Update a specific post
-//	@Description	Updates the details of a specific post
-//	@Tags			Posts
-//	@Param			id	path	int	true	"Post ID"
-//	@Accept			json
-//	@Produce		json
-//	@Success		200	{object}	utils.APIResponse
-//	@Failure		400	{object}	utils.SwaggerErrorResponse
-//	@Failure		401	{object}	utils.SwaggerErrorResponse
-//	@Router			/posts/{id} [put]
-func (c PostController) Update(w http.ResponseWriter, r *http.Request) {
-	// Handle PUT /postcontroller/{id} request
-	// You can get the request body by reading from r.Body
-	// You can send a response by writing to w
-	// file, _, err := r.FormFile("file")
-	// if err != nil {
-	// 	utils.JSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
-	// 	return
-	// }
-	// defer file.Close()
-	// // Generate a unique public ID for the file
-	// publicID := uuid.New().String()
-
-	// cloudinaryClient := cloudinary.NewCloudinaryClient()
-	// result, err := cloudinaryClient.UploadImage(file, publicID)
-	// if err != nil {
-	// 	utils.JSONResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
-	// 	return
-	// }
-	utils.JSONResponse(w, http.StatusOK, map[string]string{"error": "this is still not implemented"})
-}
-
 // UpdateTitle handles the PUT /posts/{id}/title request to update the title of a specific post.
 //
 // This endpoint allows users to update the title of a specific post identified by its ID.
@@ -288,13 +309,15 @@ func (c PostController) Update(w http.ResponseWriter, r *http.Request) {
 //	@Success		200				{object}	utils.APIResponse
 //	@Failure		400				{object}	utils.SwaggerErrorResponse
 //	@Failure		401				{object}	utils.SwaggerErrorResponse
+//	@Failure		403				{object}	utils.SwaggerErrorResponse
+//	@Failure		422				{object}	utils.SwaggerErrorResponse
 //	@Router			/posts/{id}/title [put]
 func (c PostController) UpdateTitle(w http.ResponseWriter, r *http.Request) {
 	// Parse post ID from request parameters
-	userID, err := utils.GetUserIDFromContext(r.Context())
+	user, err := auth.User(r)
 
 	if err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+		utils.HandleError(w, err, http.StatusUnauthorized)
 		return
 	}
 
@@ -335,10 +358,17 @@ func (c PostController) UpdateTitle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if post.UserID != uint(userID.(float64)) {
-		utils.HandleError(w, errors.New("you are not authorized to update post"), http.StatusUnauthorized)
+	if !policies.Can(user, policies.Update, &post) {
+		utils.HandleError(w, errors.New("you are not authorized to update post"), http.StatusForbidden)
 		return
 	}
+	// Run the new title through the content filter
+	content := contentfilter.Content{Type: "posts", ID: post.ID, AuthorID: post.UserID, Title: updateReq.Title, Body: post.Body}
+	filterResult, ok := filterContent(w, r, c.DB, content)
+	if !ok {
+		return
+	}
+
 	// Update the post title
 	post.Title = updateReq.Title
 
@@ -347,6 +377,7 @@ func (c PostController) UpdateTitle(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, result.Error, http.StatusInternalServerError)
 		return
 	}
+	applyContentResult(c.DB, content, filterResult)
 	// Return success response
 	utils.JSONResponse(w, http.StatusOK,
 		&utils.APIResponse{
@@ -369,15 +400,17 @@ func (c PostController) UpdateTitle(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Success		200	{object}	utils.SwaggerSuccessResponse
 //	@Failure		400	{object}	utils.SwaggerErrorResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
 //	@Failure		404	{object}	utils.SwaggerErrorResponse
+//	@Failure		422	{object}	utils.SwaggerErrorResponse
 //	@Failure		500	{object}	utils.SwaggerErrorResponse
 //	@Router			/posts/{id}/body [put]
 func (c PostController) UpdateBody(w http.ResponseWriter, r *http.Request) {
 	// Parse post ID from request parameters
-	userID, err := utils.GetUserIDFromContext(r.Context())
+	user, err := auth.User(r)
 
 	if err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+		utils.HandleError(w, err, http.StatusUnauthorized)
 		return
 	}
 
@@ -418,10 +451,17 @@ func (c PostController) UpdateBody(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if post.UserID != uint(userID.(float64)) {
-		utils.HandleError(w, errors.New("you are not authorized to update post"), http.StatusUnauthorized)
+	if !policies.Can(user, policies.Update, &post) {
+		utils.HandleError(w, errors.New("you are not authorized to update post"), http.StatusForbidden)
+		return
+	}
+	// Run the new body through the content filter
+	content := contentfilter.Content{Type: "posts", ID: post.ID, AuthorID: post.UserID, Title: post.Title, Body: updateReq.Body}
+	filterResult, ok := filterContent(w, r, c.DB, content)
+	if !ok {
 		return
 	}
+
 	// Update the post title
 	post.Body = updateReq.Body
 
@@ -430,9 +470,15 @@ func (c PostController) UpdateBody(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, result.Error, http.StatusInternalServerError)
 		return
 	}
+	applyContentResult(c.DB, content, filterResult)
 
 	// Perform the edit mentions operation
-	err = services.EditMentions(c.DB, post.ID, "posts", updateReq.Mentions)
+	mentions, err := services.WithoutBlockedMentions(c.DB, post.UserID, updateReq.Mentions)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	err = services.EditMentions(c.DB, post.ID, "posts", mentions)
 	if err != nil {
 		utils.HandleError(w, err, http.StatusInternalServerError)
 		return
@@ -459,15 +505,16 @@ func (c PostController) UpdateBody(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Success		200	{object}	utils.SwaggerSuccessResponse
 //	@Failure		400	{object}	utils.SwaggerErrorResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
 //	@Failure		404	{object}	utils.SwaggerErrorResponse
 //	@Failure		500	{object}	utils.SwaggerErrorResponse
 //	@Router			/posts/{id}/medias [put]
 func (c PostController) UpdateMedia(w http.ResponseWriter, r *http.Request) {
 	// Parse post ID from request parameters
-	userID, err := utils.GetUserIDFromContext(r.Context())
+	user, err := auth.User(r)
 
 	if err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+		utils.HandleError(w, err, http.StatusUnauthorized)
 		return
 	}
 
@@ -508,8 +555,8 @@ func (c PostController) UpdateMedia(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if post.UserID != uint(userID.(float64)) {
-		utils.HandleError(w, errors.New("you are not authorized to upate post"), http.StatusUnauthorized)
+	if !policies.Can(user, policies.Update, &post) {
+		utils.HandleError(w, errors.New("you are not authorized to upate post"), http.StatusForbidden)
 		return
 	}
 
@@ -539,15 +586,16 @@ func (c PostController) UpdateMedia(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Success		200	{object}	utils.SwaggerSuccessResponse
 //	@Failure		400	{object}	utils.SwaggerErrorResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
 //	@Failure		404	{object}	utils.SwaggerErrorResponse
 //	@Failure		500	{object}	utils.SwaggerErrorResponse
 //	@Router			/posts/{id}/hashtags [put]
 func (c PostController) UpdateHashtag(w http.ResponseWriter, r *http.Request) {
 	// Parse post ID from request parameters
-	userID, err := utils.GetUserIDFromContext(r.Context())
+	user, err := auth.User(r)
 
 	if err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+		utils.HandleError(w, err, http.StatusUnauthorized)
 		return
 	}
 
@@ -575,8 +623,22 @@ func (c PostController) UpdateHashtag(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var post Models.Post
+	if err := c.DB.First(&post, postID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.HandleError(w, errors.New("post not found"), http.StatusNotFound)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if !policies.Can(user, policies.Update, &post) {
+		utils.HandleError(w, errors.New("you are not authorized to update post"), http.StatusForbidden)
+		return
+	}
+
 	// Perform update in the service for the specified post ID
-	err = services.EditTags(c.DB, postID, updateReq.Hashtags, uint(userID.(float64)))
+	err = services.EditTags(c.DB, postID, updateReq.Hashtags, user.ID)
 	if err != nil {
 		if err.Error() == "post not found" {
 			utils.HandleError(w, err, http.StatusNotFound)
@@ -607,10 +669,17 @@ func (c PostController) UpdateHashtag(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Success		200	{object}	utils.SwaggerSuccessResponse
 //	@Failure		400	{object}	utils.SwaggerErrorResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
 //	@Failure		404	{object}	utils.SwaggerErrorResponse
 //	@Failure		500	{object}	utils.SwaggerErrorResponse
 //	@Router			/posts/{id}/settings [put]
 func (c *PostController) UpdatePostSettings(w http.ResponseWriter, r *http.Request) {
+	user, err := auth.User(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
 	// Parse post ID from request parameters
 	postID, err := utils.GetParam(r, "id")
 	if err != nil {
@@ -646,6 +715,10 @@ func (c *PostController) UpdatePostSettings(w http.ResponseWriter, r *http.Reque
 		}
 		return
 	}
+	if !policies.Can(user, policies.Update, &post) {
+		utils.HandleError(w, errors.New("you are not authorized to update post"), http.StatusForbidden)
+		return
+	}
 	log.Println(updateReq.IsFeatured, updateReq.IsPromoted, updateReq.FeaturedExpiry, updateReq.PromotionExpiry)
 	// Update the fields based on the provided update request if the values are not empty or null
 	if updateReq.Visibility != "" {
@@ -684,10 +757,11 @@ func (c *PostController) UpdatePostSettings(w http.ResponseWriter, r *http.Reque
 //	@Success		200	{object}	utils.SwaggerSuccessResponse
 //	@Failure		400	{object}	utils.SwaggerErrorResponse
 //	@Failure		401	{object}	utils.SwaggerErrorResponse
+//	@Failure		403	{object}	utils.SwaggerErrorResponse
 //	@Failure		404	{object}	utils.SwaggerErrorResponse
 //	@Failure		500	{object}	utils.SwaggerErrorResponse
 //	@Router			/posts/{id} [delete]
-func (c *PostController) Delete(w http.ResponseWriter, r *http.Request) {
+func (c PostController) Delete(w http.ResponseWriter, r *http.Request) {
 	// Parse post ID from request parameters
 	postID, err := utils.GetParam(r, "id")
 	if err != nil {
@@ -696,9 +770,9 @@ func (c *PostController) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get the authenticated user ID from the context
-	userID, err := utils.GetUserIDFromContext(r.Context())
+	user, err := auth.User(r)
 	if err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+		utils.HandleError(w, err, http.StatusUnauthorized)
 		return
 	}
 
@@ -715,8 +789,8 @@ func (c *PostController) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if the authenticated user is the owner of the post
-	if post.UserID != uint(userID.(float64)) {
-		utils.HandleError(w, errors.New("you are not authorized to delete this post"), http.StatusUnauthorized)
+	if !policies.Can(user, policies.Delete, &post) {
+		utils.HandleError(w, errors.New("you are not authorized to delete this post"), http.StatusForbidden)
 		return
 	}
 
@@ -731,3 +805,44 @@ func (c *PostController) Delete(w http.ResponseWriter, r *http.Request) {
 		Message: "post was deleted successfully",
 	})
 }
+
+// filterContent runs the post or comment through the content filter. It writes the error response and
+// returns false when the content is rejected or the filter fails.
+func filterContent(w http.ResponseWriter, r *http.Request, db *gorm.DB, content contentfilter.Content) (contentfilter.Result, bool) {
+	result, err := services.CheckContent(r.Context(), db, content)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return result, false
+	}
+	if result.Action == contentfilter.Reject {
+		noun := strings.TrimSuffix(content.Type, "s")
+		utils.HandleError(w, fmt.Errorf("your %s was rejected because %s", noun, result.Reason()), http.StatusUnprocessableEntity)
+		return result, false
+	}
+	return result, true
+}
+
+// applyContentResult queues held and flagged content for review once it is saved.
+func applyContentResult(db *gorm.DB, content contentfilter.Content, result contentfilter.Result) {
+	if err := services.ApplyContentResult(db, content, result); err != nil {
+		log.Printf("failed to queue %s %d for review: %v", content.Type, content.ID, err)
+	}
+}
+
+// writeTombstone responds with 410 Gone and the tombstone of a post or comment that moderators removed or hid.
+func writeTombstone(w http.ResponseWriter, subjectType string, id uint, status Models.ModerationStatus, updatedAt time.Time) {
+	message := "this content was removed for breaking the community rules"
+	if status == Models.ModerationHidden {
+		message = "this content is hidden while moderators review it"
+	}
+	utils.JSONResponse(w, http.StatusGone, utils.APIResponse{
+		Type:    "tombstone",
+		Message: message,
+		Data: utils.Tombstone{
+			ID:        id,
+			Type:      subjectType,
+			Status:    string(status),
+			UpdatedAt: updatedAt,
+		},
+	})
+}
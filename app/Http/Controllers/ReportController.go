@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"errors"
+	requests "gonga/app/Http/Requests"
+	"gonga/app/Models"
+	services "gonga/app/Services"
+	auth "gonga/packages/Auth"
+	"gonga/utils"
+	"log"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// ReportController lets users report posts, comments and users to the moderators.
+type ReportController struct {
+	DB *gorm.DB
+}
+
+// Create handles the POST /reports request to report a post, comment or user.
+//
+// The report is added to the moderation queue. Posts and comments reported by enough different users
+// are hidden until a moderator reviews them.
+//
+//	@Summary		Report content
+//	@Description	Reports a post, comment or user to the moderators
+//	@Tags			Reports
+//	@Accept			json
+//	@Produce		json
+//	@Param			createReportRequest	body		requests.CreateReportRequest	true	"Reported subject and reason"
+//	@Success		201					{object}	utils.SwaggerSuccessResponse
+//	@Failure		400					{object}	utils.SwaggerErrorResponse
+//	@Failure		401					{object}	utils.SwaggerErrorResponse
+//	@Failure		404					{object}	utils.SwaggerErrorResponse
+//	@Failure		409					{object}	utils.SwaggerErrorResponse
+//	@Failure		500					{object}	utils.SwaggerErrorResponse
+//	@Router			/reports [post]
+//	@Security		BearerAuth
+func (c ReportController) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ID(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var req requests.CreateReportRequest
+	if err := utils.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			log.Print(err.Error())
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &req); err != nil {
+		return
+	}
+
+	_, err = services.ReportSubject(c.DB, userID, req.SubjectType, req.SubjectID, Models.ReportReason(req.Reason), req.Details)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReportSubjectNotFound):
+			utils.HandleError(w, err, http.StatusNotFound)
+		case errors.Is(err, services.ErrReportOwnContent):
+			utils.HandleError(w, err, http.StatusBadRequest)
+		case errors.Is(err, services.ErrAlreadyReported):
+			utils.HandleError(w, err, http.StatusConflict)
+		default:
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusCreated, utils.APIResponse{
+		Type:    "success",
+		Message: "thank you, our moderators will review your report",
+	})
+}
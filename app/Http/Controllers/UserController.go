@@ -1,11 +1,17 @@
 package controllers
 
 import (
+	"errors"
+	auth "gonga/packages/Auth"
 	"gonga/utils"
 	"net/http"
+	"strconv"
 
 	requests "gonga/app/Http/Requests"
 	"gonga/app/Models"
+	policies "gonga/app/Policies"
+	services "gonga/app/Services"
+	"gonga/config"
 
 	"gorm.io/gorm"
 )
@@ -28,13 +34,15 @@ func (uc UserController) Index(w http.ResponseWriter, r *http.Request) {
 	var users []Models.User
 	var response utils.APIResponse
 
-	paginationScope, err := utils.Paginate(r, uc.DB, &users, &response)
+	// Banned users are not listed
+	query := uc.DB.Scopes(services.HideBanned("id"))
+	paginationScope, err := utils.Paginate(r, query, &users, &response)
 	if err != nil {
 		utils.JSONResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	db := paginationScope(uc.DB)
+	db := paginationScope(query)
 	if err := db.Find(&users).Error; err != nil {
 		utils.JSONResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -73,6 +81,15 @@ func (uc UserController) Show(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	// Banned users and users who blocked the caller or were blocked by them are shown as not found
+	viewerID, _ := auth.ID(r)
+	if blocked, err := services.IsBlocked(uc.DB, viewerID, user.ID); err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	} else if blocked || user.IsBanned() {
+		http.Error(w, gorm.ErrRecordNotFound.Error(), http.StatusNotFound)
+		return
+	}
 	// Return successful response with the user data
 	utils.JSONResponse(w, http.StatusOK, utils.APIResponse{
 		Type: "success",
@@ -95,7 +112,9 @@ func (uc UserController) Create(w http.ResponseWriter, r *http.Request) {
 //	@Param			updateReq	body		requests.UpdateUserRequest	true	"Update request body"
 //	@Success		200			{object}	utils.APIResponse			"success"
 //	@Failure		400			{object}	utils.APIResponse			"error"
+//	@Failure		403			{object}	utils.APIResponse			"error"
 //	@Failure		404			{object}	utils.APIResponse			"error"
+//	@Failure		409			{object}	utils.APIResponse			"error"
 //	@Failure		500			{object}	utils.APIResponse			"error"
 //	@Router			/users/{username} [PUT]
 //	@Security		BearerAuth
@@ -114,6 +133,16 @@ func (uc UserController) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	authUser, err := auth.User(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	if !policies.Can(authUser, policies.Update, &user) {
+		utils.HandleError(w, errors.New("you are not authorized to update this user"), http.StatusForbidden)
+		return
+	}
+
 	// Parse update request from request body
 	var updateReq requests.UpdateUserRequest
 	if err := utils.DecodeRequestBody(r, &updateReq); err != nil {
@@ -170,9 +199,22 @@ func (uc UserController) Update(w http.ResponseWriter, r *http.Request) {
 		user.Education = updateReq.Education
 	}
 
-	// Save updated user to the database
-	if err := uc.DB.Save(&user).Error; err != nil {
-		utils.HandleError(w, err, http.StatusInternalServerError)
+	// Save updated user to the database. Changing the username revokes the verification badge, it was
+	// granted to the old name, so the rename is saved together with the rest of the profile.
+	err = uc.DB.Transaction(func(tx *gorm.DB) error {
+		if updateReq.Username != "" {
+			if err := services.ChangeUsername(tx, &user, updateReq.Username); err != nil {
+				return err
+			}
+		}
+		return tx.Save(&user).Error
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrUsernameTaken) {
+			utils.HandleError(w, err, http.StatusConflict)
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -183,17 +225,82 @@ func (uc UserController) Update(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-//	@Summary		Delete a user
-//	@Description	Delete a user by their ID
+// Delete handles the DELETE /users/{id} request to delete the authenticated user's account.
+//
+// The user has to confirm with their password, and their two-factor code when it is enabled. The account
+// is logged out everywhere and purged after the grace period. Logging in before then cancels the deletion.
+// Users can only delete their own account, administrators use DELETE /admin/users/{id}.
+//
+//	@Summary		Delete own account
+//	@Description	Schedules the deletion of the authenticated user's account after the grace period
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path	string	true	"ID of the user to delete"
-//	@Success		204	"success"
-//	@Failure		404	{object}	utils.APIResponse	"error"
-//	@Failure		500	{object}	utils.APIResponse	"error"
+//	@Param			id						path		string							true	"ID of the authenticated user"
+//	@Param			deleteAccountRequest	body		requests.DeleteAccountRequest	true	"Password and two-factor code"
+//	@Success		202						{object}	utils.SwaggerSuccessResponse
+//	@Failure		400						{object}	utils.SwaggerErrorResponse
+//	@Failure		401						{object}	utils.SwaggerErrorResponse
+//	@Failure		403						{object}	utils.SwaggerErrorResponse
+//	@Failure		500						{object}	utils.SwaggerErrorResponse
 //	@Router			/users/{id} [DELETE]
 //	@Security		BearerAuth
 func (uc UserController) Delete(w http.ResponseWriter, r *http.Request) {
-	// Handle DELETE /users/{id} request
+	id, err := utils.GetParam(r, "id")
+	if err != nil {
+		utils.HandleError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := auth.User(r)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusUnauthorized)
+		return
+	}
+	if id != strconv.FormatUint(uint64(user.ID), 10) {
+		utils.HandleError(w, errors.New("you can only delete your own account"), http.StatusForbidden)
+		return
+	}
+
+	var deleteReq requests.DeleteAccountRequest
+	if err := utils.DecodeJSONBody(w, r, &deleteReq); err != nil {
+		var mr *utils.MalformedRequest
+		if errors.As(err, &mr) {
+			utils.JSONResponse(w, mr.Status(), map[string]string{"error": mr.Error()})
+		} else {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := utils.ValidateRequest(w, &deleteReq); err != nil {
+		return
+	}
+
+	// Deleting an account can't be undone after the grace period, so the user has to authenticate again
+	if err := utils.VerifyPassword(user.Password, deleteReq.Password); err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, utils.ErrorResponse{
+			Errors: map[string]string{"Password": utils.Validation["current_password"].(string)},
+		})
+		return
+	}
+	if user.HasTwoFactorEnabled() {
+		if err := services.VerifyTwoFactorCode(uc.DB, user, deleteReq.Code); err != nil {
+			utils.JSONResponse(w, http.StatusBadRequest, utils.ErrorResponse{
+				Errors: map[string]string{"Code": err.Error()},
+			})
+			return
+		}
+	}
+
+	purgeAt, err := services.ScheduleAccountDeletion(uc.DB, user, config.LoadAuthConfig().DeletionGracePeriod)
+	if err != nil {
+		utils.HandleError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusAccepted, utils.APIResponse{
+		Type:    "success",
+		Message: "your account will be deleted, log in again before then to cancel",
+		Data:    map[string]interface{}{"deletion_scheduled_at": purgeAt},
+	})
 }
@@ -1,17 +1,27 @@
 package middlewares
 
 import (
-	"context"
 	"errors"
+	"gonga/app/Models"
+	services "gonga/app/Services"
+	"gonga/database"
+	"gonga/packages"
+	auth "gonga/packages/Auth"
 	"gonga/utils"
 	"net/http"
-	// "strings"
+	"strings"
 )
 
 // AuthMiddleware is a middleware function that checks if a user is authenticated.
 // If the user is not authenticated, it returns an error response with status code 401.
 // If the user is authenticated, it calls the next middleware/handler in the chain.
 //
+// The token has to belong to an active session, tokens of revoked sessions are rejected.
+// The authenticated caller is available to handlers through auth.ID(r), auth.User(r) and auth.Current(r).
+//
+// Suspended and banned users are rejected with status code 403. Restricted users can only read and send
+// requests that manage their own account, log out or appeal the restriction.
+//
 // Example usage:
 //
 //	router := packages.NewRouter()
@@ -20,21 +30,84 @@ import (
 // This will add the AuthMiddleware to the UserHandler function when the "/api/users" endpoint is accessed with the "GET" method.
 func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		const (
-			userIDKey utils.ContextKey = "userID"
-		)
 		// Check if user is authenticated and get the user ID
-		authenticated, userID := utils.IsAuthenticate(r)
-		if !authenticated {
+		principal, session, ok := authenticate(r)
+		if !ok {
 			utils.HandleError(w, errors.New("unauthorized"), http.StatusUnauthorized)
 			return
 		}
+		switch session.State {
+		case Models.AccountSuspended:
+			utils.HandleError(w, errors.New("this account has been suspended"), http.StatusForbidden)
+			return
+		case Models.AccountBanned:
+			utils.HandleError(w, errors.New("this account has been banned"), http.StatusForbidden)
+			return
+		case Models.AccountRestricted:
+			if !allowedWhileRestricted(r) {
+				utils.HandleError(w, errors.New("this account is restricted"), http.StatusForbidden)
+				return
+			}
+		}
 
-		// Set the user ID in the request context
-		ctx := context.WithValue(r.Context(), userIDKey, userID)
-		r = r.WithContext(ctx)
+		// Set the principal in the request context
+		r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+		logUser(r, principal.UserID)
 
 		// Call the next middleware/handler
 		next.ServeHTTP(w, r)
 	})
 }
+
+// OptionalAuthMiddleware sets the principal like AuthMiddleware when the request carries a valid access token,
+// and lets the request through anonymously otherwise. Public endpoints use it to tailor their response to the
+// caller, for example to hide the content of blocked users.
+func OptionalAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal, session, ok := authenticate(r); ok && session.State != Models.AccountSuspended && session.State != Models.AccountBanned {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+			logUser(r, principal.UserID)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// restrictedPaths are the path prefixes restricted users can still send write requests to, without the API version.
+var restrictedPaths = []string{"/me/", "/user/", "/logout", "/appeals"}
+
+// authenticate returns the principal and session of the access token in the Authorization header if the
+// token is valid and its session is active.
+func authenticate(r *http.Request) (*auth.Principal, *services.Session, bool) {
+	claims, err := auth.ParseAccessToken(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, nil, false
+	}
+	userID, err := auth.UserIDFromClaims(claims)
+	if err != nil {
+		return nil, nil, false
+	}
+	sessionID, _ := claims["jti"].(string)
+	session, ok := services.FindSession(database.DB, userID, sessionID)
+	if !ok {
+		return nil, nil, false
+	}
+	return auth.NewPrincipal(database.DB, userID, session.ID, session.Scopes, []Models.Role{session.Role}), session, true
+}
+
+// allowedWhileRestricted reports whether a restricted user may send the request.
+func allowedWhileRestricted(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	path := r.URL.Path
+	if version := packages.RequestVersion(r); version != "" {
+		path = strings.TrimPrefix(path, "/"+version)
+	}
+	for _, prefix := range restrictedPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -9,7 +9,8 @@ func CorsMiddleware(next http.Handler) http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         // Set headers for allowed methods, headers, and origins
         w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
-        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, X-Request-ID")
+        w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
         w.Header().Set("Access-Control-Allow-Origin", "*")
 
         // Handle pre-flight requests
@@ -0,0 +1,166 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	services "gonga/app/Services"
+	"gonga/config"
+	"gonga/database"
+	auth "gonga/packages/Auth"
+	idempotency "gonga/packages/Idempotency"
+	logger "gonga/packages/Logger"
+	"gonga/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// replayedHeaders are the response headers stored with an idempotency key and replayed to retries.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+var (
+	idempotencyStore     idempotency.Store
+	idempotencyStoreOnce sync.Once
+)
+
+// IdempotencyMiddleware makes retries of a request safe. The response to the first request with an
+// Idempotency-Key header is stored and replayed to every retry with the same key, instead of running
+// the request again. Keys are scoped to the user and the route and expire after IDEMPOTENCY_TTL_HOURS.
+//
+// A key sent again with a different body, or while its first request is still running, is rejected
+// with status code 409. Requests without the header and guests are not affected. Server errors and
+// panics are not stored, the request can be retried with the same key. A request that never completes
+// holds its key for IDEMPOTENCY_LEASE_SECONDS. It needs the AuthMiddleware to run first.
+//
+// Example usage:
+//
+//	router.Post("/posts", PostController.Create, middlewares.AuthMiddleware, middlewares.IdempotencyMiddleware)
+func IdempotencyMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		userID, err := auth.ID(r)
+		if key == "" || err != nil {
+			next(w, r)
+			return
+		}
+		if len(key) > 255 {
+			utils.HandleError(w, errors.New("the Idempotency-Key header must not be longer than 255 characters"), http.StatusBadRequest)
+			return
+		}
+
+		// The body is read to hash it, with the limit of DecodeJSONBody
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, utils.MaxJSONBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				utils.HandleError(w, errors.New("Request body must not be larger than 1MB"), http.StatusRequestEntityTooLarge)
+			} else {
+				utils.HandleError(w, err, http.StatusBadRequest)
+			}
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		cfg := config.LoadIdempotencyConfig()
+		store := defaultIdempotencyStore()
+		scopedKey := hashHex(strconv.FormatUint(uint64(userID), 10) + " " + r.Method + " " + r.URL.Path + " " + key)
+		record, err := store.Reserve(scopedKey, hashHex(string(body)), cfg.Lease)
+		if err != nil {
+			utils.HandleError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if record != nil {
+			switch {
+			case record.RequestHash != hashHex(string(body)):
+				utils.HandleError(w, errors.New("the Idempotency-Key was already used for a different request"), http.StatusConflict)
+			case !record.Completed:
+				utils.HandleError(w, errors.New("a request with this Idempotency-Key is still being processed"), http.StatusConflict)
+			default:
+				for name, values := range record.Response.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.Response.Status)
+				_, _ = w.Write(record.Response.Body)
+			}
+			return
+		}
+
+		release := func() {
+			if err := store.Release(scopedKey); err != nil {
+				logger.Default().ErrorContext(r.Context(), "failed to release the idempotency key", "error", err)
+			}
+		}
+		// A panicking handler gives the key up too, the RecoveryMiddleware answers the request
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				release()
+				panic(recovered)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		next(recorder, r)
+
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		if recorder.status >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		response := idempotency.Response{Status: recorder.status, Header: http.Header{}, Body: recorder.body.Bytes()}
+		for _, name := range replayedHeaders {
+			if values := w.Header().Values(name); len(values) > 0 {
+				response.Header[name] = values
+			}
+		}
+		if err := store.Complete(scopedKey, response, cfg.TTL); err != nil {
+			logger.Default().ErrorContext(r.Context(), "failed to store the idempotent response", "error", err)
+		}
+	}
+}
+
+// defaultIdempotencyStore returns the store configured by IDEMPOTENCY_STORE.
+func defaultIdempotencyStore() idempotency.Store {
+	idempotencyStoreOnce.Do(func() {
+		if config.LoadIdempotencyConfig().Store == "database" {
+			idempotencyStore = services.IdempotencyStore{DB: database.DB}
+		} else {
+			idempotencyStore = idempotency.NewMemoryStore()
+		}
+	})
+	return idempotencyStore
+}
+
+func hashHex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// responseRecorder copies the status and body of a response as it is written.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
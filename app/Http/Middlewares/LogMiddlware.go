@@ -1,17 +1,91 @@
 package middlewares
 
 import (
+	"context"
+	logger "gonga/packages/Logger"
+	"gonga/utils"
+	"log/slog"
 	"net/http"
+	"sync/atomic"
 	"time"
 
-	"github.com/pterm/pterm"
+	"github.com/gorilla/mux"
 )
 
+// accessLogKey is the context key of the *accessLogEntry of a request.
+type accessLogKey struct{}
+
+// accessLogEntry is what the handlers of a request tell the LogMiddleware about it. The auth
+// middlewares put the principal in a copy of the request, the entry is how it reaches the log.
+type accessLogEntry struct {
+	userID uint64
+	route  atomic.Value
+}
+
+// LogMiddleware logs every request after it was served, with its status, size, duration, route name
+// and the ID of the authenticated user. Requests that failed with a server error are logged at the
+// error level. It runs after the RequestIDMiddleware to log the request ID.
+//
+// It wraps the whole router, so that requests no route matches are logged too. The route name is
+// recorded from inside the router by the RouteLogMiddleware.
 func LogMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		pterm.Println(pterm.Gray(time.Now().Format("2006-01-02 15:04:05")), "Incoming request", pterm.Magenta(r.Method), r.URL.Path, "from", r.RemoteAddr)
+		start := time.Now()
+		entry := &accessLogEntry{}
+		rw := utils.NewResponseWriter(w)
+
+		next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), accessLogKey{}, entry)))
+
+		status := rw.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("route", entry.routeName()),
+			slog.Int("status", status),
+			slog.Int("bytes", rw.Bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", utils.ClientIP(r)),
+			slog.String("user_agent", r.UserAgent()),
+		}
+		if userID := atomic.LoadUint64(&entry.userID); userID != 0 {
+			attrs = append(attrs, slog.Uint64("user_id", userID))
+		}
+		logger.Default().LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+// logUser records the authenticated user of the request in its access log line.
+func logUser(r *http.Request, userID uint) {
+	if entry, ok := r.Context().Value(accessLogKey{}).(*accessLogEntry); ok {
+		atomic.StoreUint64(&entry.userID, uint64(userID))
+	}
+}
 
-		// Call the next handler
+// RouteLogMiddleware records the name of the matched route, or its path template when it has no
+// name, in the access log line of the request.
+func RouteLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if entry, ok := r.Context().Value(accessLogKey{}).(*accessLogEntry); ok {
+			if route := mux.CurrentRoute(r); route != nil {
+				name := route.GetName()
+				if name == "" {
+					name, _ = route.GetPathTemplate()
+				}
+				entry.route.Store(name)
+			}
+		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+func (e *accessLogEntry) routeName() string {
+	name, _ := e.route.Load().(string)
+	return name
+}
@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"errors"
+	policies "gonga/app/Policies"
+	auth "gonga/packages/Auth"
+	"gonga/utils"
+	"net/http"
+)
+
+// PermissionMiddleware returns a middleware that only lets users through whose role has the permission.
+// It has to run after the AuthMiddleware.
+//
+// Example usage:
+//
+//	router.Get("/admin/users", AdminUserController.Index, middlewares.AuthMiddleware, middlewares.PermissionMiddleware(policies.ViewUsers))
+func PermissionMiddleware(permission policies.Permission) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := auth.User(r)
+			if err != nil {
+				utils.HandleError(w, errors.New("unauthorized"), http.StatusUnauthorized)
+				return
+			}
+
+			if !policies.HasPermission(user, permission) {
+				utils.HandleError(w, errors.New("forbidden"), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"gonga/config"
+	logger "gonga/packages/Logger"
+	"gonga/utils"
+	"net/http"
+	"runtime/debug"
+)
+
+// RecoveryMiddleware turns a panic in a handler into a 500 error response, instead of closing the
+// connection without one. The panic is logged with the request ID, and with its stack trace when
+// APP_DEBUG is true. In debug mode the response also carries the panic message.
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := utils.NewResponseWriter(w)
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// The server aborts the response on purpose with this panic, let it through
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			debugMode := config.LoadAppConfig().Debug
+			attrs := []any{"method", r.Method, "path", r.URL.Path, "panic", fmt.Sprint(recovered)}
+			if debugMode {
+				attrs = append(attrs, "stack", string(debug.Stack()))
+			}
+			logger.Default().ErrorContext(r.Context(), "panic serving the request", attrs...)
+
+			// Part of the response was already sent, it can't be replaced by the error anymore
+			if rw.Written() {
+				return
+			}
+			err := errors.New("internal server error")
+			if debugMode {
+				err = fmt.Errorf("%v", recovered)
+			}
+			utils.HandleError(rw, err, http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
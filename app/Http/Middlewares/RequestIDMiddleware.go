@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"gonga/utils"
+	"net/http"
+)
+
+// RequestIDMiddleware gives every request an ID, which is sent back in the X-Request-ID header and
+// added to the logs of the request. An X-Request-ID sent by the client or a proxy in front of the
+// API is kept when it is up to 128 letters, digits, dashes, underscores, dots and colons, so the
+// request can be followed across services. Handlers read it with utils.RequestID(r.Context()).
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = utils.NewRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(utils.WithRequestID(r.Context(), id)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -1,17 +1,121 @@
 package middlewares
 
 import (
+	"errors"
+	"fmt"
+	"gonga/config"
+	auth "gonga/packages/Auth"
+	logger "gonga/packages/Logger"
+	ratelimit "gonga/packages/RateLimit"
+	"gonga/utils"
+	"math"
 	"net/http"
-	"golang.org/x/time/rate"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// throttleScopes numbers the limits created by Throttle, every limit counts the requests of a client separately.
+var throttleScopes int64
+
+var (
+	defaultThrottle     func(http.HandlerFunc) http.HandlerFunc
+	defaultThrottleOnce sync.Once
+)
+
+// ThrottleMiddleware limits every client to RATE_LIMIT_DEFAULT requests across all routes. It runs
+// before the route is authenticated, so clients are told apart by the session of their access token,
+// when its signature is valid, or else their IP.
 func ThrottleMiddleware(next http.Handler) http.Handler {
-	limiter := rate.NewLimiter(1, 1)
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !limiter.Allow() {
-			http.Error(w, "The request cannot be served due to the rate limit having been exhausted for the resource", http.StatusTooManyRequests)
-			return
+	defaultThrottleOnce.Do(func() {
+		defaultThrottle = func(next http.HandlerFunc) http.HandlerFunc { return next }
+		if spec := config.LoadRateLimitConfig().Default; spec != "" {
+			defaultThrottle = newThrottle("default", spec+",token")
 		}
-		next.ServeHTTP(w, r)
 	})
+	return defaultThrottle(next.ServeHTTP)
+}
+
+// Throttle returns a middleware that limits the requests of each client to a route, or to all routes of
+// a group together. The spec is "throttle:requests,minutes[,key]", the throttle: prefix is optional:
+//
+//	throttle:60,1       60 requests a minute per user, per IP for guests
+//	throttle:6,1,ip     6 requests a minute per IP
+//	throttle:30,1,token 30 requests a minute per session of a valid access token, per IP without one
+//
+// Limiting per user needs the AuthMiddleware to run first. Responses carry the RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers, rejected requests get a 429 with Retry-After.
+// It panics when the spec is invalid.
+//
+// Example usage:
+//
+//	router.Post("/forgot-password", PasswordResetLinkController.Create, middlewares.Throttle("throttle:6,1,ip"))
+func Throttle(spec string) func(http.HandlerFunc) http.HandlerFunc {
+	return newThrottle(strconv.FormatInt(atomic.AddInt64(&throttleScopes, 1), 10), spec)
+}
+
+func newThrottle(scope, spec string) func(http.HandlerFunc) http.HandlerFunc {
+	parts := strings.Split(strings.TrimPrefix(spec, "throttle:"), ",")
+	key := "user"
+	if len(parts) == 3 {
+		key = strings.TrimSpace(parts[2])
+		parts = parts[:2]
+	}
+	limit, err := ratelimit.ParseLimit(strings.Join(parts, ","))
+	if err != nil {
+		panic(err)
+	}
+	if key != "user" && key != "ip" && key != "token" {
+		panic(fmt.Sprintf("middlewares: unknown throttle key %q, expected user, ip or token", key))
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			result, err := ratelimit.Default().Take(scope+":"+throttleKey(r, key), limit)
+			if err != nil {
+				// Don't turn the API away when the store is unavailable
+				logger.Default().ErrorContext(r.Context(), "failed to check the rate limit", "error", err)
+				next(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+				utils.HandleError(w, errors.New("too many requests, please try again later"), http.StatusTooManyRequests)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// throttleKey identifies the client of the request by the key kind: user, ip or token.
+func throttleKey(r *http.Request, key string) string {
+	switch key {
+	case "user":
+		if userID, err := auth.ID(r); err == nil {
+			return "user:" + strconv.FormatUint(uint64(userID), 10)
+		}
+	case "token":
+		// Only a token with a valid signature counts, made up tokens would get a new limit each
+		if principal, err := auth.Current(r); err == nil && principal.TokenID != "" {
+			return "token:" + principal.TokenID
+		}
+		if claims, err := auth.ParseAccessToken(r.Header.Get("Authorization")); err == nil {
+			if sessionID, _ := claims["jti"].(string); sessionID != "" {
+				return "token:" + sessionID
+			}
+		}
+	}
+	return "ip:" + utils.ClientIP(r)
+}
+
+// seconds rounds the duration up to whole seconds.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
 }
@@ -0,0 +1,7 @@
+package requests
+
+// ResolveReportRequest represents the request payload for resolving a moderation case
+type ResolveReportRequest struct {
+	Action string `json:"action" validate:"required,oneof=dismiss remove warn suspend reinstate"`
+	Note   string `json:"note" validate:"required_if=Action warn,max=1000"`
+}
@@ -0,0 +1,27 @@
+package requests
+
+import "time"
+
+// SuspendUserRequest represents the request payload for suspending a user
+type SuspendUserRequest struct {
+	Reason    string     `json:"reason" validate:"required,max=500"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty,gt"`
+}
+
+// UpdateAccountStateRequest represents the request payload for changing the account state of a user
+type UpdateAccountStateRequest struct {
+	State     string     `json:"state" validate:"required,oneof=active restricted suspended banned"`
+	Reason    string     `json:"reason" validate:"required_unless=State active,max=500"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty,gt"`
+}
+
+// UpdateRoleRequest represents the request payload for changing the role of a user
+type UpdateRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+// GrantBadgeRequest represents the request payload for granting a verification badge to a user
+type GrantBadgeRequest struct {
+	Badge string `json:"badge" validate:"required,oneof=notable organization staff"`
+	Note  string `json:"note" validate:"max=500"`
+}
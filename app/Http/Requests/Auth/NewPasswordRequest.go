@@ -0,0 +1,8 @@
+package requests
+
+type NewPasswordRequest struct {
+	Email                string `json:"email" validate:"required,email"`
+	Token                string `json:"token" validate:"required"`
+	Password             string `json:"password" validate:"required"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,eqcsfield=Password"`
+}
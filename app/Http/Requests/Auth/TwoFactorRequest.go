@@ -0,0 +1,19 @@
+package requests
+
+// ConfirmTwoFactorRequest confirms a pending two-factor enrollment with the first code from the authenticator app.
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// DisableTwoFactorRequest disables two-factor authentication after the user re-enters their password.
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// TwoFactorLoginRequest finishes a login that was interrupted by a two-factor challenge.
+// Either Code or RecoveryCode must be provided.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required_without=RecoveryCode"`
+	RecoveryCode   string `json:"recovery_code" validate:"required_without=Code"`
+}
@@ -0,0 +1,8 @@
+package requests
+
+// CreateAppealRequest represents the request payload for appealing the state of an account.
+// Suspended and banned users, who can't log in, send the appeal token they got from the login instead.
+type CreateAppealRequest struct {
+	Message     string `json:"message" validate:"required,max=2000"`
+	AppealToken string `json:"appeal_token"`
+}
@@ -0,0 +1,6 @@
+package requests
+
+// CreateMutedWordRequest represents the request payload for muting a word or phrase
+type CreateMutedWordRequest struct {
+	Word string `json:"word" validate:"required,max=100"`
+}
@@ -0,0 +1,9 @@
+package requests
+
+// CreateReportRequest represents the request payload for reporting a post, comment or user
+type CreateReportRequest struct {
+	SubjectType string `json:"subject_type" validate:"required,oneof=posts comments users"`
+	SubjectID   uint   `json:"subject_id" validate:"required"`
+	Reason      string `json:"reason" validate:"required,oneof=spam harassment hate_speech violence sexual_content misinformation other"`
+	Details     string `json:"details" validate:"omitempty,max=1000"`
+}
@@ -0,0 +1,8 @@
+package requests
+
+// DeleteAccountRequest represents the request payload for deleting the authenticated user's account.
+// Code is the current two-factor code and only required when two-factor authentication is enabled.
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code"`
+}
@@ -0,0 +1,12 @@
+package requests
+
+// UpdateEmailRequest represents the request payload for changing the authenticated user's email address
+type UpdateEmailRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// ConfirmEmailRequest represents the request payload for confirming a new email address
+type ConfirmEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,8 @@
+package requests
+
+// UpdatePasswordRequest represents the request payload for changing the authenticated user's password
+type UpdatePasswordRequest struct {
+	CurrentPassword      string `json:"current_password" validate:"required"`
+	Password             string `json:"password" validate:"required"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,eqcsfield=Password"`
+}
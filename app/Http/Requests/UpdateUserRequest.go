@@ -4,6 +4,7 @@ import "time"
 
 // UpdateUserRequest represents the request payload for updating a user's profile information
 type UpdateUserRequest struct {
+	Username           string    `json:"username" validate:"omitempty,alphanum,min=3,max=30"`
 	FirstName          string    `json:"first_name" validate:"required,max=50"`
 	LastName           string    `json:"last_name"`
 	AvatarURL          string    `json:"avatar_url"`
@@ -0,0 +1,13 @@
+package responses
+
+import "time"
+
+// AccountStateResponse is returned with status code 403 when a suspended or banned user logs in.
+// The appeal token lets the user appeal the decision through POST /appeals.
+type AccountStateResponse struct {
+	State       string     `json:"state"`
+	Reason      string     `json:"reason"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	AppealToken string     `json:"appeal_token"`
+	Message     string     `json:"message"`
+}
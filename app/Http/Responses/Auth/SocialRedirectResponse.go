@@ -0,0 +1,7 @@
+package responses
+
+// SocialRedirectResponse contains the provider URL that starts a social login.
+type SocialRedirectResponse struct {
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
@@ -0,0 +1,21 @@
+package responses
+
+// TwoFactorChallengeResponse is returned by the login endpoint when the user has two-factor authentication enabled.
+type TwoFactorChallengeResponse struct {
+	TwoFactor      bool   `json:"two_factor"`
+	ChallengeToken string `json:"challenge_token"`
+	Message        string `json:"message"`
+}
+
+// TwoFactorSetupResponse contains the secret of a pending two-factor enrollment.
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+	Message         string `json:"message"`
+}
+
+// TwoFactorRecoveryCodesResponse contains freshly generated recovery codes. They are only shown once.
+type TwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Message       string   `json:"message"`
+}
@@ -0,0 +1,29 @@
+package Models
+
+// AccountState is the standing of a user account. Moderators change it instead of deleting the account,
+// so the account's content and relations stay intact.
+type AccountState string
+
+const (
+	// AccountActive accounts have no restrictions.
+	AccountActive AccountState = "active"
+	// AccountRestricted accounts can log in and read, but can't post, comment or interact with other users.
+	AccountRestricted AccountState = "restricted"
+	// AccountSuspended accounts can't log in until the suspension expires or is lifted.
+	AccountSuspended AccountState = "suspended"
+	// AccountBanned accounts can't log in and their content is hidden.
+	AccountBanned AccountState = "banned"
+)
+
+// AccountStates lists every account state, from the least to the most severe.
+var AccountStates = []AccountState{AccountActive, AccountRestricted, AccountSuspended, AccountBanned}
+
+// IsValid reports whether s is a known account state.
+func (s AccountState) IsValid() bool {
+	for _, state := range AccountStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
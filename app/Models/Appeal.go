@@ -0,0 +1,20 @@
+package Models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Appeal is a user's request to lift the restriction, suspension or ban of their account. Every appeal
+// opens a ModerationCase with the subject type "appeals", a user can have one appeal under review at a time.
+type Appeal struct {
+	gorm.Model
+	UserID  uint         `json:"user_id" gorm:"not null;index"`
+	CaseID  uint         `json:"case_id" gorm:"not null;index"`
+	State   AccountState `json:"state" gorm:"type:varchar(20);not null"` // the state being appealed
+	Reason  string       `json:"reason" gorm:"type:text"`                // why the account got the state
+	Message string       `json:"message" gorm:"type:text;not null"`
+}
+
+func (Appeal) TableName() string {
+	return "appeals"
+}
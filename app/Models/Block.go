@@ -0,0 +1,17 @@
+package Models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Block is recorded for the user who blocked, but it hides the two users from each other in both directions.
+type Block struct {
+	gorm.Model
+	BlockerID uint  `json:"blocker_id" gorm:"not null;uniqueIndex:idx_blocks_pair"`
+	BlockedID uint  `json:"blocked_id" gorm:"not null;uniqueIndex:idx_blocks_pair;index"`
+	Blocked   *User `json:"blocked,omitempty" gorm:"foreignKey:BlockedID"`
+}
+
+func (Block) TableName() string {
+	return "blocks"
+}
@@ -1,12 +1,17 @@
 package Models
 
 import (
+	"encoding/json"
+
 	"gorm.io/gorm"
 )
 
+// DeletedUsername is shown as the author of the comments and tags whose user was deleted.
+const DeletedUsername = "[deleted]"
+
 type Comment struct {
 	gorm.Model
-	UserID    uint       `json:"user_id"`
+	UserID    *uint      `json:"user_id"` // NULL once the author was deleted
 	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	PostID    uint       `json:"post_id"`
 	Post      *Post      `json:"post,omitempty" gorm:"foreignKey:PostID;"`
@@ -16,10 +21,35 @@ type Comment struct {
 	Parent    *Comment   `json:"parent,omitempty"`
 	Childrens []*Comment `json:"childrens,omitempty" gorm:"foreignKey:ParentID"`
 	Mentions  []*Mention `json:"mentions" gorm:"polymorphic:Owner;"`
+	// ModerationStatus is ModerationVisible unless the comment was hidden by reports or removed by a moderator
+	ModerationStatus ModerationStatus `json:"moderation_status" gorm:"type:varchar(20);not null;default:visible;index"`
 }
 
-
-
 func (Comment) TableName() string {
 	return "comments"
 }
+
+// AuthorID returns the ID of the author, or 0 when the author was deleted.
+func (c Comment) AuthorID() uint {
+	if c.UserID == nil {
+		return 0
+	}
+	return *c.UserID
+}
+
+// MarshalJSON renders the author of a comment whose user was deleted as DeletedUsername.
+func (c Comment) MarshalJSON() ([]byte, error) {
+	type comment Comment
+	if c.UserID != nil {
+		return json.Marshal(comment(c))
+	}
+	return json.Marshal(struct {
+		comment
+		User deletedAuthor `json:"user"`
+	}{comment(c), deletedAuthor{Username: DeletedUsername}})
+}
+
+// deletedAuthor is the user of the comments and tags whose author was deleted.
+type deletedAuthor struct {
+	Username string
+}
@@ -0,0 +1,34 @@
+package Models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportStatus is the progress of a DataExport.
+type ExportStatus string
+
+const (
+	ExportPending    ExportStatus = "pending"
+	ExportProcessing ExportStatus = "processing"
+	ExportReady      ExportStatus = "ready"
+	ExportFailed     ExportStatus = "failed"
+)
+
+// DataExport is an archive of a user's personal data. Path is the ZIP file on disk, Token holds the
+// SHA-256 hash of the token in the emailed download link.
+type DataExport struct {
+	gorm.Model
+	UserID      uint         `json:"-" gorm:"index;not null"`
+	Status      ExportStatus `json:"status" gorm:"type:varchar(20);not null;default:pending"`
+	Path        string       `json:"-"`
+	Token       string       `json:"-" gorm:"index"`
+	Size        int64        `json:"size"`
+	CompletedAt *time.Time   `json:"completed_at"`
+	ExpiresAt   *time.Time   `json:"expires_at"`
+}
+
+func (DataExport) TableName() string {
+	return "data_exports"
+}
@@ -0,0 +1,21 @@
+package Models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EmailChange is a pending change of a user's email address. The new address is only applied once
+// the token sent to it is confirmed. Token holds the SHA-256 hash of that token.
+type EmailChange struct {
+	gorm.Model
+	UserID    uint      `gorm:"index;not null"`
+	NewEmail  string    `gorm:"not null"`
+	Token     string    `gorm:"unique;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
+
+func (EmailChange) TableName() string {
+	return "email_changes"
+}
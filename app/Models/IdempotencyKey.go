@@ -0,0 +1,23 @@
+package Models
+
+import (
+	"time"
+)
+
+// IdempotencyKey is an Idempotency-Key sent with a request and the response to replay to its retries.
+// Hash is the SHA-256 hash of the key, scoped to the user and the route.
+type IdempotencyKey struct {
+	ID          uint   `gorm:"primaryKey"`
+	Hash        string `gorm:"type:char(64);uniqueIndex;not null"`
+	RequestHash string `gorm:"type:char(64);not null"`
+	Completed   bool   `gorm:"not null;default:false"`
+	Status      int    `gorm:"not null;default:0"`
+	Header      string `gorm:"type:text"`
+	Body        []byte
+	ExpiresAt   time.Time `gorm:"index;not null"`
+	CreatedAt   time.Time
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,20 @@
+package Models
+
+import (
+	"gorm.io/gorm"
+)
+
+// LoginAttempt records every login attempt for throttling and auditing.
+type LoginAttempt struct {
+	gorm.Model
+	Username   string `json:"username" gorm:"type:varchar(255);index;not null"`
+	IPAddress  string `json:"ip_address" gorm:"type:varchar(64);index;not null"`
+	UserAgent  string `json:"user_agent"`
+	UserID     *uint  `json:"user_id"`
+	Successful bool   `json:"successful"`
+	Reason     string `json:"reason"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
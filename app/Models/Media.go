@@ -1,17 +1,19 @@
 package Models
 
 import (
-    "gorm.io/gorm"
+	"gorm.io/gorm"
 )
 
 type Media struct {
-    gorm.Model
-    URL       string `json:"url"`
-    Type      string `json:"type"`
-    OwnerID   uint   `json:"owner_id"`
-    OwnerType string `json:"owner_type"` // posts, comments, users, etc.
+	gorm.Model
+	URL       string `json:"url"`
+	Type      string `json:"type"`
+	PublicID  string `json:"-"`                    // Cloudinary public ID of the uploaded file
+	UserID    uint   `json:"user_id" gorm:"index"` // the user who uploaded the file
+	OwnerID   uint   `json:"owner_id"`
+	OwnerType string `json:"owner_type"` // posts, comments, users, etc.
 }
 
 func (Media) TableName() string {
-    return "medias"
-}
\ No newline at end of file
+	return "medias"
+}
@@ -0,0 +1,78 @@
+package Models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModerationStatus tells whether a post or comment is shown.
+type ModerationStatus string
+
+const (
+	// ModerationVisible content is shown to everyone.
+	ModerationVisible ModerationStatus = "visible"
+	// ModerationHidden content received enough reports to be hidden until a moderator reviews it.
+	ModerationHidden ModerationStatus = "hidden"
+	// ModerationRemoved content was removed by a moderator.
+	ModerationRemoved ModerationStatus = "removed"
+)
+
+// CaseStatus is the progress of a ModerationCase.
+type CaseStatus string
+
+const (
+	CaseOpen     CaseStatus = "open"
+	CaseResolved CaseStatus = "resolved"
+)
+
+// Resolution is what a moderator did to resolve a ModerationCase.
+type Resolution string
+
+const (
+	ResolutionDismiss   Resolution = "dismiss"
+	ResolutionRemove    Resolution = "remove"
+	ResolutionWarn      Resolution = "warn"
+	ResolutionSuspend   Resolution = "suspend"
+	ResolutionReinstate Resolution = "reinstate"
+)
+
+// ModerationCase is an entry of the moderation queue. It collects the reports of one post, comment or user
+// until a moderator resolves it. Reports after the resolution open a new case. Appeals of account states
+// are queued as cases too, with the appeal as their subject.
+type ModerationCase struct {
+	gorm.Model
+	SubjectType   string             `json:"subject_type" gorm:"type:varchar(20);not null;index:idx_moderation_cases_subject;uniqueIndex:idx_moderation_cases_open_subject"` // posts, comments, users or appeals
+	SubjectID     uint               `json:"subject_id" gorm:"not null;index:idx_moderation_cases_subject;uniqueIndex:idx_moderation_cases_open_subject"`
+	SubjectUserID uint               `json:"subject_user_id" gorm:"index"` // author of the post or comment, the reported user or the appellant
+	Status        CaseStatus         `json:"status" gorm:"type:varchar(20);not null;default:open;index"`
+	ReportCount   int                `json:"report_count"`
+	AssigneeID    *uint              `json:"assignee_id" gorm:"index"`
+	ClaimedAt     *time.Time         `json:"claimed_at"`
+	Resolution    Resolution         `json:"resolution,omitempty" gorm:"type:varchar(20)"`
+	ResolvedByID  *uint              `json:"resolved_by_id"`
+	ResolvedAt    *time.Time         `json:"resolved_at"`
+	Open          *bool              `json:"-" gorm:"uniqueIndex:idx_moderation_cases_open_subject"` // true while open, NULL once resolved: one open case per subject
+	Reports       []Report           `json:"reports,omitempty" gorm:"foreignKey:CaseID"`
+	Appeal        *Appeal            `json:"appeal,omitempty" gorm:"foreignKey:CaseID"`
+	Actions       []ModerationAction `json:"actions,omitempty" gorm:"foreignKey:CaseID"`
+}
+
+func (ModerationCase) TableName() string {
+	return "moderation_cases"
+}
+
+// ModerationAction is an entry of the moderation audit log. ModeratorID is 0 for actions the system took,
+// such as hiding content that received too many reports.
+type ModerationAction struct {
+	ID          uint      `json:"id" gorm:"primarykey"`
+	CaseID      uint      `json:"case_id" gorm:"not null;index"`
+	ModeratorID uint      `json:"moderator_id" gorm:"index"`
+	Action      string    `json:"action" gorm:"type:varchar(20);not null"`
+	Note        string    `json:"note" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (ModerationAction) TableName() string {
+	return "moderation_actions"
+}
@@ -0,0 +1,17 @@
+package Models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Mute hides the muted user's content from the muter only. The muted user is not told.
+type Mute struct {
+	gorm.Model
+	MuterID uint  `json:"muter_id" gorm:"not null;uniqueIndex:idx_mutes_pair"`
+	MutedID uint  `json:"muted_id" gorm:"not null;uniqueIndex:idx_mutes_pair"`
+	Muted   *User `json:"muted,omitempty" gorm:"foreignKey:MutedID"`
+}
+
+func (Mute) TableName() string {
+	return "mutes"
+}
@@ -0,0 +1,16 @@
+package Models
+
+import (
+	"gorm.io/gorm"
+)
+
+// MutedWord is a word or phrase whose posts and comments are hidden from the user who muted it.
+type MutedWord struct {
+	gorm.Model
+	UserID uint   `json:"-" gorm:"not null;uniqueIndex:idx_muted_words_user_word"`
+	Word   string `json:"word" gorm:"type:varchar(100);not null;uniqueIndex:idx_muted_words_user_word"`
+}
+
+func (MutedWord) TableName() string {
+	return "muted_words"
+}
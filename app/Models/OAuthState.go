@@ -0,0 +1,22 @@
+package Models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OAuthState stores a pending social login between the redirect to the provider and the callback.
+// It holds the PKCE code verifier and OpenID Connect nonce that belong to the state parameter.
+type OAuthState struct {
+	gorm.Model
+	State        string    `gorm:"type:varchar(128);uniqueIndex;not null"`
+	Provider     string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	Nonce        string    `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"not null"`
+}
+
+func (OAuthState) TableName() string {
+	return "oauth_states"
+}
@@ -6,12 +6,17 @@ import (
 	"gorm.io/gorm"
 )
 
+// PersonalAccessToken is an issued access token (a session). Token holds the SHA-256 hash of the
+// token's "jti" claim, a token is only accepted while its row exists and has not expired.
+// Scopes is a space separated list of the abilities granted to the token, "*" grants all of them.
 type PersonalAccessToken struct {
 	gorm.Model
-	Name  string `gorm:"not null"`
-	Token string `gorm:"unique;not null"`
-    LastUsedAt time.Time
-	ExpiresAt time.Time
+	UserID     uint   `gorm:"index;not null"`
+	Name       string `gorm:"not null"`
+	Token      string `gorm:"unique;not null"`
+	Scopes     string `gorm:"not null;default:'*'"`
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
 }
 
 func (PersonalAccessToken) TableName() string {
@@ -35,6 +35,8 @@ type Post struct {
 	IsFeatured      bool       `json:"is_featured"`
 	FeaturedExpiry  time.Time  `json:"featured_expiry"`
 	Visibility      Visibility `json:"visibility"`
+	// ModerationStatus is ModerationVisible unless the post was hidden by reports or removed by a moderator
+	ModerationStatus ModerationStatus `json:"moderation_status" gorm:"type:varchar(20);not null;default:visible;index"`
 }
 
 func (Post) TableName() string {
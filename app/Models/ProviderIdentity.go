@@ -0,0 +1,19 @@
+package Models
+
+import (
+	"gorm.io/gorm"
+)
+
+// ProviderIdentity links a user to an account at an external OAuth2/OpenID Connect provider.
+type ProviderIdentity struct {
+	gorm.Model
+	UserID   uint   `json:"user_id" gorm:"index;not null"`
+	User     *User  `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Provider string `json:"provider" gorm:"type:varchar(64);uniqueIndex:idx_provider_subject;not null"`
+	Subject  string `json:"subject" gorm:"type:varchar(255);uniqueIndex:idx_provider_subject;not null"`
+	Email    string `json:"email"`
+}
+
+func (ProviderIdentity) TableName() string {
+	return "provider_identities"
+}
@@ -0,0 +1,33 @@
+package Models
+
+import (
+	"gorm.io/gorm"
+)
+
+// ReportReason is the category a user picks when reporting content.
+type ReportReason string
+
+const (
+	ReasonSpam           ReportReason = "spam"
+	ReasonHarassment     ReportReason = "harassment"
+	ReasonHateSpeech     ReportReason = "hate_speech"
+	ReasonViolence       ReportReason = "violence"
+	ReasonSexualContent  ReportReason = "sexual_content"
+	ReasonMisinformation ReportReason = "misinformation"
+	ReasonOther          ReportReason = "other"
+)
+
+// Report is a single user's report of a post, comment or user. Reports of the same subject are
+// collected in a ModerationCase, a user can report a case only once.
+type Report struct {
+	gorm.Model
+	CaseID     uint         `json:"case_id" gorm:"not null;uniqueIndex:idx_reports_case_reporter"`
+	ReporterID uint         `json:"reporter_id" gorm:"not null;uniqueIndex:idx_reports_case_reporter;index"`
+	Reporter   *User        `json:"reporter,omitempty" gorm:"foreignKey:ReporterID"`
+	Reason     ReportReason `json:"reason" gorm:"type:varchar(30);not null"`
+	Details    string       `json:"details" gorm:"type:text"`
+}
+
+func (Report) TableName() string {
+	return "reports"
+}
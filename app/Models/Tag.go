@@ -1,6 +1,8 @@
 package Models
 
 import (
+	"encoding/json"
+
 	"gorm.io/gorm"
 )
 
@@ -12,7 +14,7 @@ type Tag struct {
 	Description  string  `json:"description"`
 	Color        string  `json:"color"`
 	Slug         string  `json:"slug"`
-	UserID       uint    `json:"user_id"`
+	UserID       *uint   `json:"user_id"` // NULL once the user who created the tag was deleted
 	User         User    `json:"user" gorm:"foreignKey:UserID"`
 	// ParentID     uint    `json:"-"`
 	// Parent       *Tag    `json:"-" gorm:"foreignKey:ParentID"`
@@ -24,3 +26,15 @@ type Tag struct {
 func (Tag) TableName() string {
 	return "tags"
 }
+
+// MarshalJSON renders the user of a tag whose creator was deleted as DeletedUsername.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	type tag Tag
+	if t.UserID != nil {
+		return json.Marshal(tag(t))
+	}
+	return json.Marshal(struct {
+		tag
+		User deletedAuthor `json:"user"`
+	}{tag(t), deletedAuthor{Username: DeletedUsername}})
+}
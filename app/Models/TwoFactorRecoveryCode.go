@@ -0,0 +1,20 @@
+package Models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactorRecoveryCode is a single-use code that lets a user finish a two-factor
+// login without their authenticator app. Only the bcrypt hash of the code is stored.
+type TwoFactorRecoveryCode struct {
+	gorm.Model
+	UserID uint       `json:"user_id" gorm:"index;not null"`
+	Code   string     `json:"-" gorm:"not null"`
+	UsedAt *time.Time `json:"used_at"`
+}
+
+func (TwoFactorRecoveryCode) TableName() string {
+	return "two_factor_recovery_codes"
+}
@@ -8,31 +8,82 @@ import (
 
 type User struct {
 	gorm.Model
-	Username           string     `gorm:"uniqueIndex:idx_username_length;not null;unique"`
-	Email              string     `gorm:"unique; not null"`
-	Password           string     `gorm:"not null"`
-	FirstName          string     `gorm:"not null"`
-	LastName           string     `gorm:"not null"`
-	AvatarURL          string     `json:"avatar_url"`
-	Bio                string     `json:"bio"`
-	Gender             string     `json:"gender"`
-	MobileNo           string     `json:"mobile_no" gorm:"type:varchar(255);null"`
-	MobileNoCode       string     `json:"mobile_no_code"`
-	Birthday           *time.Time `json:"birthday"`
-	Country            string     `json:"country"`
-	City               string     `json:"city"`
-	Posts              []Post     `json:"posts" gorm:"foreignKey:UserID"`
-	Comments           []Comment  `json:"comments" gorm:"foreignKey:UserID"`
-	FollowersList      []Follow   `json:"followers_list" gorm:"foreignKey:FollowerID"`
-	FollowingList      []Follow   `json:"following_list" gorm:"foreignKey:FollowingID"`
-	BackgroundImageURL string     `json:"background_image_url"`
-	WebsiteURL         string     `json:"website_url"`
-	Occupation         string     `json:"occupation"`
-	Education          string     `json:"education"`
-	EmailVerified      bool       `json:"email_verified"`
+	Username             string       `gorm:"uniqueIndex:idx_username_length;not null;unique"`
+	Email                string       `gorm:"unique; not null"`
+	Password             string       `json:"-" gorm:"not null"`
+	FirstName            string       `gorm:"not null"`
+	LastName             string       `gorm:"not null"`
+	AvatarURL            string       `json:"avatar_url"`
+	Bio                  string       `json:"bio"`
+	Gender               string       `json:"gender"`
+	MobileNo             string       `json:"mobile_no" gorm:"type:varchar(255);null"`
+	MobileNoCode         string       `json:"mobile_no_code"`
+	Birthday             *time.Time   `json:"birthday"`
+	Country              string       `json:"country"`
+	City                 string       `json:"city"`
+	Posts                []Post       `json:"posts" gorm:"foreignKey:UserID"`
+	Comments             []Comment    `json:"comments" gorm:"foreignKey:UserID"`
+	FollowersList        []Follow     `json:"followers_list" gorm:"foreignKey:FollowerID"`
+	FollowingList        []Follow     `json:"following_list" gorm:"foreignKey:FollowingID"`
+	BackgroundImageURL   string       `json:"background_image_url"`
+	WebsiteURL           string       `json:"website_url"`
+	Occupation           string       `json:"occupation"`
+	Education            string       `json:"education"`
+	EmailVerified        bool         `json:"email_verified"`
+	TwoFactorSecret      string       `json:"-"`
+	TwoFactorConfirmedAt *time.Time   `json:"-"`
+	TwoFactorLastCounter int64        `json:"-"`
+	Role                 Role         `json:"role" gorm:"type:varchar(20);not null;default:user"`
+	State                AccountState `json:"state" gorm:"type:varchar(20);not null;default:active;index"`
+	StateReason          string       `json:"-"`
+	StateChangedAt       *time.Time   `json:"-"`
+	StateExpiresAt       *time.Time   `json:"state_expires_at,omitempty"`
+	DeletionScheduledAt  *time.Time   `json:"deletion_scheduled_at,omitempty" gorm:"index"`
+	Badge                BadgeType    `json:"badge,omitempty" gorm:"type:varchar(20)"`
+	VerifiedAt           *time.Time   `json:"verified_at,omitempty"`
 	// Interests          []string  `json:"interests"`
 }
 
 func (User) TableName() string {
 	return "users"
 }
+
+// HasTwoFactorEnabled reports whether the user has confirmed a TOTP secret.
+func (u User) HasTwoFactorEnabled() bool {
+	return u.TwoFactorSecret != "" && u.TwoFactorConfirmedAt != nil
+}
+
+// CurrentState returns the state of the account, taking its expiry into account.
+func (u User) CurrentState() AccountState {
+	if u.State == "" || (u.StateExpiresAt != nil && time.Now().After(*u.StateExpiresAt)) {
+		return AccountActive
+	}
+	return u.State
+}
+
+// IsSuspended reports whether the user is suspended or banned. Such users can't log in.
+func (u User) IsSuspended() bool {
+	state := u.CurrentState()
+	return state == AccountSuspended || state == AccountBanned
+}
+
+// IsBanned reports whether the user is banned. The content of banned users is hidden.
+func (u User) IsBanned() bool {
+	return u.CurrentState() == AccountBanned
+}
+
+// IsRestricted reports whether the user is restricted to reading and managing their own account.
+func (u User) IsRestricted() bool {
+	return u.CurrentState() == AccountRestricted
+}
+
+// IsVerified reports whether the user has a verification badge.
+func (u User) IsVerified() bool {
+	return u.Badge != ""
+}
+
+// IsPendingDeletion reports whether the user asked for their account to be deleted.
+// The account is purged once DeletionScheduledAt has passed, unless the user logs in before.
+func (u User) IsPendingDeletion() bool {
+	return u.DeletionScheduledAt != nil
+}
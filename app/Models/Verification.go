@@ -0,0 +1,53 @@
+package Models
+
+import "time"
+
+// BadgeType is the kind of verification badge shown next to a user's name.
+type BadgeType string
+
+const (
+	// BadgeNotable is for public figures and creators.
+	BadgeNotable BadgeType = "notable"
+	// BadgeOrganization is for brands, companies and other organizations.
+	BadgeOrganization BadgeType = "organization"
+	// BadgeStaff is for people working on the platform.
+	BadgeStaff BadgeType = "staff"
+)
+
+// BadgeTypes lists every badge type.
+var BadgeTypes = []BadgeType{BadgeNotable, BadgeOrganization, BadgeStaff}
+
+// IsValid reports whether b is a known badge type.
+func (b BadgeType) IsValid() bool {
+	for _, badge := range BadgeTypes {
+		if b == badge {
+			return true
+		}
+	}
+	return false
+}
+
+// VerificationAction is what happened to a user's badge in an entry of the verification history.
+type VerificationAction string
+
+const (
+	VerificationGranted VerificationAction = "granted"
+	VerificationRevoked VerificationAction = "revoked"
+)
+
+// VerificationEvent is an entry of a user's verification history. ActorID is 0 for changes the system made,
+// such as revoking the badge after a username change. Username is the user's name at the time of the event.
+type VerificationEvent struct {
+	ID        uint               `json:"id" gorm:"primarykey"`
+	UserID    uint               `json:"user_id" gorm:"not null;index"`
+	Username  string             `json:"username" gorm:"not null"`
+	Badge     BadgeType          `json:"badge" gorm:"type:varchar(20);not null"`
+	Action    VerificationAction `json:"action" gorm:"type:varchar(20);not null"`
+	ActorID   uint               `json:"actor_id" gorm:"index"`
+	Note      string             `json:"note" gorm:"type:text"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+func (VerificationEvent) TableName() string {
+	return "verification_events"
+}
@@ -0,0 +1,15 @@
+package policies
+
+import "gonga/app/Models"
+
+// commentPolicy lets authors edit and delete their comments. Moderators can delete any comment but not edit it.
+func commentPolicy(user *Models.User, action string, comment *Models.Comment) bool {
+	switch action {
+	case Update:
+		return comment.AuthorID() == user.ID
+	case Delete:
+		return comment.AuthorID() == user.ID || HasPermission(user, ModerateContent)
+	default:
+		return false
+	}
+}
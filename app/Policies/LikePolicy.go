@@ -0,0 +1,13 @@
+package policies
+
+import "gonga/app/Models"
+
+// likePolicy only lets users take back their own likes.
+func likePolicy(user *Models.User, action string, like *Models.Like) bool {
+	switch action {
+	case Delete:
+		return like.UserID == user.ID
+	default:
+		return false
+	}
+}
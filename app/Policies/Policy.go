@@ -0,0 +1,94 @@
+package policies
+
+import (
+	"gonga/app/Models"
+)
+
+// Permission is an ability granted to a role, independent of any particular record.
+type Permission string
+
+const (
+	// ModerateContent allows deleting posts and comments of other users.
+	ModerateContent Permission = "content.moderate"
+	// ViewUsers allows listing all users, including suspended ones, in the admin API.
+	ViewUsers Permission = "users.view"
+	// SuspendUsers allows restricting, suspending and reinstating users.
+	SuspendUsers Permission = "users.suspend"
+	// BanUsers allows banning users and lifting bans.
+	BanUsers Permission = "users.ban"
+	// ManageUsers allows deleting users, changing their role and granting verification badges.
+	ManageUsers Permission = "users.manage"
+)
+
+// Actions asked of the policies.
+const (
+	Update     = "update"
+	Delete     = "delete"
+	Suspend    = "suspend"
+	Ban        = "ban"
+	Verify     = "verify"
+	ChangeRole = "change-role"
+)
+
+// rolePermissions maps every role to the permissions it has. Higher roles repeat the permissions
+// of lower roles so that each role can be read on its own.
+var rolePermissions = map[Models.Role][]Permission{
+	Models.RoleUser:      {},
+	Models.RoleModerator: {ModerateContent, ViewUsers, SuspendUsers},
+	Models.RoleAdmin:     {ModerateContent, ViewUsers, SuspendUsers, BanUsers, ManageUsers},
+}
+
+// HasPermission reports whether the user's role grants the permission. Suspended users have no permissions.
+func HasPermission(user *Models.User, permission Permission) bool {
+	if user == nil || user.IsSuspended() {
+		return false
+	}
+	for _, granted := range rolePermissions[user.Role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the user has one of the roles.
+func HasRole(user *Models.User, roles ...Models.Role) bool {
+	if user == nil {
+		return false
+	}
+	for _, role := range roles {
+		if user.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Can reports whether the user may perform the action on the subject.
+//
+// Example usage:
+//
+//	if !policies.Can(user, policies.Update, &post) {
+//		utils.HandleError(w, errors.New("you are not authorized to update post"), http.StatusForbidden)
+//		return
+//	}
+//
+// Subjects without a policy are denied.
+func Can(user *Models.User, action string, subject interface{}) bool {
+	if user == nil || user.IsSuspended() {
+		return false
+	}
+
+	switch s := subject.(type) {
+	case *Models.Post:
+		return postPolicy(user, action, s)
+	case *Models.Comment:
+		return commentPolicy(user, action, s)
+	case *Models.Like:
+		return likePolicy(user, action, s)
+	case *Models.User:
+		return userPolicy(user, action, s)
+	default:
+		return false
+	}
+}
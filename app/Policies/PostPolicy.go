@@ -0,0 +1,15 @@
+package policies
+
+import "gonga/app/Models"
+
+// postPolicy lets authors edit and delete their posts. Moderators can delete any post but not edit it.
+func postPolicy(user *Models.User, action string, post *Models.Post) bool {
+	switch action {
+	case Update:
+		return post.UserID == user.ID
+	case Delete:
+		return post.UserID == user.ID || HasPermission(user, ModerateContent)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,40 @@
+package policies
+
+import "gonga/app/Models"
+
+// userPolicy lets users edit and delete their own account. Staff can act on other accounts, but
+// only on accounts with a lower role, so moderators can't suspend admins or each other.
+func userPolicy(user *Models.User, action string, target *Models.User) bool {
+	self := user.ID == target.ID
+
+	switch action {
+	case Update:
+		return self || HasPermission(user, ManageUsers)
+	case Delete:
+		return self || (HasPermission(user, ManageUsers) && outranks(user, target))
+	case Suspend:
+		return !self && HasPermission(user, SuspendUsers) && outranks(user, target)
+	case Ban:
+		return !self && HasPermission(user, BanUsers) && outranks(user, target)
+	case Verify:
+		return HasPermission(user, ManageUsers)
+	case ChangeRole:
+		return !self && HasPermission(user, ManageUsers)
+	default:
+		return false
+	}
+}
+
+// outranks reports whether user has a higher role than target.
+func outranks(user, target *Models.User) bool {
+	return rank(user.Role) > rank(target.Role)
+}
+
+func rank(role Models.Role) int {
+	for i, r := range Models.Roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
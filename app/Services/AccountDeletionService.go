@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gonga/app/Models"
+	"gonga/config"
+	cloudinary "gonga/packages/Cloudinary"
+	mail "gonga/packages/Mail"
+	"html"
+	"log"
+	"time"
+
+	gongaCloudinary "gonga/contracts/Cloudinary"
+
+	"gorm.io/gorm"
+)
+
+// deletedCommentBody replaces the body of a deleted user's comment that other users replied to.
+const deletedCommentBody = "[deleted]"
+
+// FileDeleter deletes uploaded files from the storage they were uploaded to.
+type FileDeleter interface {
+	DeleteFile(publicID string) (*gongaCloudinary.CloudinaryResponse, error)
+}
+
+// ScheduleAccountDeletion marks the user's account for deletion after the grace period and logs them out
+// everywhere. Logging in before the grace period is over cancels the deletion.
+func ScheduleAccountDeletion(db *gorm.DB, user *Models.User, gracePeriod time.Duration) (time.Time, error) {
+	purgeAt := time.Now().Add(gracePeriod)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("deletion_scheduled_at", purgeAt).Error; err != nil {
+			return err
+		}
+		return RevokeOtherSessions(tx, user.ID, "")
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	go func(email string) {
+		if err := sendAccountDeletionNotice(email, purgeAt); err != nil {
+			log.Println("failed to send account deletion notice:", err)
+		}
+	}(user.Email)
+
+	return purgeAt, nil
+}
+
+// CancelAccountDeletion cancels a scheduled deletion of the user's account.
+// It reports whether a deletion was pending.
+func CancelAccountDeletion(db *gorm.DB, user *Models.User) (bool, error) {
+	if !user.IsPendingDeletion() {
+		return false, nil
+	}
+	if err := db.Model(user).Update("deletion_scheduled_at", nil).Error; err != nil {
+		return false, err
+	}
+	user.DeletionScheduledAt = nil
+	return true, nil
+}
+
+// PurgeDueAccounts purges every account whose deletion grace period is over and returns how many
+// accounts were purged. Accounts that fail are retried on the next run.
+func PurgeDueAccounts(ctx context.Context, db *gorm.DB, files FileDeleter) (int, error) {
+	var userIDs []uint
+	err := db.Unscoped().Model(&Models.User{}).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", time.Now()).
+		Pluck("id", &userIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	var firstErr error
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		if err := PurgeUser(db, files, userID); err != nil {
+			log.Printf("failed to purge user %d: %v", userID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		purged++
+	}
+	return purged, firstErr
+}
+
+// PurgeUser permanently deletes the user and their data.
+//
+// Posts are deleted together with their comments, likes, mentions, hashtags and media. The user's comments
+// on other posts are deleted, unless someone replied to them, then they are anonymized to keep the thread.
+// Likes, mentions, follows, blocks, mutes, media files, data exports, sessions and every other record that
+// belongs to the user are deleted. Uploaded files are removed from the file storage before any row is deleted,
+// so a failed upload deletion can be retried.
+func PurgeUser(db *gorm.DB, files FileDeleter, userID uint) error {
+	var user Models.User
+	if err := db.Unscoped().First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	var postIDs []uint
+	if err := db.Unscoped().Model(&Models.Post{}).Where("user_id = ?", userID).Pluck("id", &postIDs).Error; err != nil {
+		return err
+	}
+
+	// Comments on the user's posts go with the posts
+	var deletedCommentIDs []uint
+	if err := db.Unscoped().Model(&Models.Comment{}).
+		Where("post_id IN ? OR (user_id = ? AND id NOT IN (?))", nonEmpty(postIDs), userID,
+			db.Unscoped().Model(&Models.Comment{}).Select("parent_id").Where("parent_id IS NOT NULL AND (user_id IS NULL OR user_id <> ?)", userID)).
+		Pluck("id", &deletedCommentIDs).Error; err != nil {
+		return err
+	}
+	var anonymizedCommentIDs []uint
+	if err := db.Unscoped().Model(&Models.Comment{}).
+		Where("user_id = ? AND id NOT IN ?", userID, nonEmpty(deletedCommentIDs)).
+		Pluck("id", &anonymizedCommentIDs).Error; err != nil {
+		return err
+	}
+	userCommentIDs := append(append([]uint{}, deletedCommentIDs...), anonymizedCommentIDs...)
+
+	var medias []Models.Media
+	if err := db.Unscoped().
+		Where("user_id = ?", userID).
+		Or("owner_type = ? AND owner_id = ?", "users", userID).
+		Or("owner_type = ? AND owner_id IN ?", "posts", nonEmpty(postIDs)).
+		Or("owner_type = ? AND owner_id IN ?", "comments", nonEmpty(deletedCommentIDs)).
+		Find(&medias).Error; err != nil {
+		return err
+	}
+	for _, media := range medias {
+		publicID := media.PublicID
+		if publicID == "" {
+			publicID = cloudinary.PublicIDFromURL(media.URL)
+		}
+		if publicID == "" {
+			continue
+		}
+		if _, err := files.DeleteFile(publicID); err != nil {
+			return fmt.Errorf("failed to delete media %d: %w", media.ID, err)
+		}
+	}
+	if err := deleteDataExports(db, db.Where("user_id = ?", userID)); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		tx = tx.Unscoped().Session(&gorm.Session{})
+		steps := []func() *gorm.DB{
+			func() *gorm.DB {
+				return tx.Where("user_id = ?", userID).
+					Or("likeable_type = ? AND likeable_id IN ?", "posts", nonEmpty(postIDs)).
+					Or("likeable_type = ? AND likeable_id IN ?", "comments", nonEmpty(deletedCommentIDs)).
+					Delete(&Models.Like{})
+			},
+			func() *gorm.DB {
+				return tx.Where("user_id = ?", userID).
+					Or("owner_type = ? AND owner_id IN ?", "posts", nonEmpty(postIDs)).
+					Or("owner_type = ? AND owner_id IN ?", "comments", nonEmpty(userCommentIDs)).
+					Delete(&Models.Mention{})
+			},
+			func() *gorm.DB { return tx.Where("id IN ?", nonEmpty(mediaIDs(medias))).Delete(&Models.Media{}) },
+			func() *gorm.DB { return tx.Exec("DELETE FROM post_hashtags WHERE post_id IN ?", nonEmpty(postIDs)) },
+			func() *gorm.DB {
+				return tx.Model(&Models.Comment{}).Where("id IN ?", nonEmpty(anonymizedCommentIDs)).
+					Updates(map[string]interface{}{"user_id": nil, "body": deletedCommentBody})
+			},
+			func() *gorm.DB { return tx.Where("id IN ?", nonEmpty(deletedCommentIDs)).Delete(&Models.Comment{}) },
+			func() *gorm.DB { return tx.Where("id IN ?", nonEmpty(postIDs)).Delete(&Models.Post{}) },
+			func() *gorm.DB {
+				return tx.Where("follower_id = ? OR following_id = ?", userID, userID).Delete(&Models.Follow{})
+			},
+			func() *gorm.DB {
+				return tx.Where("blocker_id = ? OR blocked_id = ?", userID, userID).Delete(&Models.Block{})
+			},
+			func() *gorm.DB {
+				return tx.Where("muter_id = ? OR muted_id = ?", userID, userID).Delete(&Models.Mute{})
+			},
+			func() *gorm.DB { return tx.Where("reporter_id = ?", userID).Delete(&Models.Report{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&Models.MutedWord{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&Models.Appeal{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&Models.VerificationEvent{}) },
+			func() *gorm.DB { return tx.Model(&Models.Tag{}).Where("user_id = ?", userID).Update("user_id", nil) },
+			func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&Models.PersonalAccessToken{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&Models.TwoFactorRecoveryCode{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&Models.ProviderIdentity{}) },
+			func() *gorm.DB { return tx.Where("user_id = ?", userID).Delete(&Models.EmailChange{}) },
+			func() *gorm.DB { return tx.Where("email = ?", user.Email).Delete(&Models.PasswordReset{}) },
+			func() *gorm.DB {
+				return tx.Where("user_id = ? OR username IN ?", userID, []string{user.Username, user.Email}).
+					Delete(&Models.LoginAttempt{})
+			},
+			func() *gorm.DB { return tx.Delete(&user) },
+		}
+		for _, step := range steps {
+			if err := step().Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func mediaIDs(medias []Models.Media) []uint {
+	ids := make([]uint, len(medias))
+	for i, media := range medias {
+		ids[i] = media.ID
+	}
+	return ids
+}
+
+// nonEmpty returns ids, or a list with the unused ID 0 when ids is empty, so "IN ?" stays valid SQL.
+func nonEmpty(ids []uint) []uint {
+	if len(ids) == 0 {
+		return []uint{0}
+	}
+	return ids
+}
+
+func sendAccountDeletionNotice(email string, purgeAt time.Time) error {
+	appConfig := config.LoadAppConfig()
+	date := purgeAt.Format("January 2, 2006")
+	textContent := fmt.Sprintf("Your %s account will be deleted on %s. "+
+		"If you change your mind, log in before then and the deletion is cancelled.", appConfig.Name, date)
+	htmlContent := fmt.Sprintf("<p>Your %s account will be deleted on <strong>%s</strong>.</p>"+
+		"<p>If you change your mind, log in before then and the deletion is cancelled.</p>",
+		html.EscapeString(appConfig.Name), date)
+
+	noticeEmail := &mail.Mailable{
+		To: []string{email},
+		Content: struct {
+			Subject string
+			Html    string
+			Text    string
+		}{
+			Subject: "Your account is scheduled for deletion",
+			Text:    textContent,
+			Html:    htmlContent,
+		},
+	}
+
+	return noticeEmail.Send()
+}
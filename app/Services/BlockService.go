@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"gonga/app/Models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCannotBlockSelf = errors.New("you cannot block or mute yourself")
+	ErrBlocked         = errors.New("you cannot interact with this user")
+)
+
+// BlockUser blocks the user for the blocker and removes the follows between them in both directions.
+// Blocking a user twice is not an error.
+func BlockUser(db *gorm.DB, blockerID, blockedID uint) error {
+	if blockerID == blockedID {
+		return ErrCannotBlockSelf
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		block := Models.Block{BlockerID: blockerID, BlockedID: blockedID}
+		if err := tx.Where(&block).FirstOrCreate(&block).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().
+			Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)",
+				blockerID, blockedID, blockedID, blockerID).
+			Delete(&Models.Follow{}).Error
+	})
+}
+
+// UnblockUser removes the blocker's block of the user. Follows removed by the block are not restored.
+func UnblockUser(db *gorm.DB, blockerID, blockedID uint) error {
+	return db.Unscoped().Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&Models.Block{}).Error
+}
+
+// MuteUser hides the user's content from the muter. Muting a user twice is not an error.
+func MuteUser(db *gorm.DB, muterID, mutedID uint) error {
+	if muterID == mutedID {
+		return ErrCannotBlockSelf
+	}
+	mute := Models.Mute{MuterID: muterID, MutedID: mutedID}
+	return db.Where(&mute).FirstOrCreate(&mute).Error
+}
+
+// UnmuteUser removes the muter's mute of the user.
+func UnmuteUser(db *gorm.DB, muterID, mutedID uint) error {
+	return db.Unscoped().Where("muter_id = ? AND muted_id = ?", muterID, mutedID).Delete(&Models.Mute{}).Error
+}
+
+// IsBlocked reports whether either of the two users blocked the other.
+func IsBlocked(db *gorm.DB, userID, otherID uint) (bool, error) {
+	if userID == 0 || otherID == 0 || userID == otherID {
+		return false, nil
+	}
+	var count int64
+	err := db.Model(&Models.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// WithoutBlockedMentions returns the mentions without the users that blocked the author or that the author blocked.
+func WithoutBlockedMentions(db *gorm.DB, authorID uint, mentions []Models.Mention) ([]Models.Mention, error) {
+	if len(mentions) == 0 {
+		return mentions, nil
+	}
+
+	var blockedIDs []uint
+	if err := db.Model(&Models.Block{}).Where("blocker_id = ?", authorID).Pluck("blocked_id", &blockedIDs).Error; err != nil {
+		return nil, err
+	}
+	var blockerIDs []uint
+	if err := db.Model(&Models.Block{}).Where("blocked_id = ?", authorID).Pluck("blocker_id", &blockerIDs).Error; err != nil {
+		return nil, err
+	}
+	hidden := make(map[uint]bool, len(blockedIDs)+len(blockerIDs))
+	for _, id := range append(blockedIDs, blockerIDs...) {
+		hidden[id] = true
+	}
+
+	allowed := make([]Models.Mention, 0, len(mentions))
+	for _, mention := range mentions {
+		if !hidden[mention.UserID] {
+			allowed = append(allowed, mention)
+		}
+	}
+	return allowed, nil
+}
+
+// HideBlocked is a query scope that leaves out the rows whose column references a user who blocked
+// the viewer or whom the viewer blocked. A viewer ID of 0, an anonymous viewer, hides nothing.
+//
+// Example usage:
+//
+//	db.Scopes(services.HideBlocked(viewerID, "posts.user_id")).Find(&posts)
+func HideBlocked(viewerID uint, column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db
+		}
+		query := db.Session(&gorm.Session{NewDB: true})
+		return db.
+			Where(notIn(column), query.Model(&Models.Block{}).Select("blocked_id").Where("blocker_id = ?", viewerID)).
+			Where(notIn(column), query.Model(&Models.Block{}).Select("blocker_id").Where("blocked_id = ?", viewerID))
+	}
+}
+
+// HideBlockedAndMuted is HideBlocked that also leaves out the rows of users the viewer muted.
+func HideBlockedAndMuted(viewerID uint, column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db
+		}
+		query := db.Session(&gorm.Session{NewDB: true})
+		return HideBlocked(viewerID, column)(db).
+			Where(notIn(column), query.Model(&Models.Mute{}).Select("muted_id").Where("muter_id = ?", viewerID))
+	}
+}
+
+// notIn is the condition that the column is not in the subquery. Rows without a user, like the comments
+// of deleted users, are kept: NOT IN is never true for NULL.
+func notIn(column string) string {
+	return "(" + column + " IS NULL OR " + column + " NOT IN (?))"
+}
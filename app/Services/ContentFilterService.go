@@ -0,0 +1,219 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"gonga/app/Models"
+	"gonga/config"
+	contentfilter "gonga/packages/ContentFilter"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxMutedWords is how many words a user can mute.
+const maxMutedWords = 200
+
+var (
+	ErrMutedWordLimit = fmt.Errorf("you cannot mute more than %d words", maxMutedWords)
+	ErrMutedWordTaken = errors.New("you already muted this word")
+)
+
+var (
+	bannedWords     *contentfilter.Matcher
+	bannedWordsOnce sync.Once
+
+	extraContentRules   []contentfilter.Rule
+	extraContentRulesMu sync.RWMutex
+)
+
+// UseContentRule adds a rule to the content filter, for example a contentfilter.Classified rule with a
+// spam classifier. Rules run after the configured rules, in the order they were added.
+func UseContentRule(rule contentfilter.Rule) {
+	extraContentRulesMu.Lock()
+	defer extraContentRulesMu.Unlock()
+	extraContentRules = append(extraContentRules, rule)
+}
+
+// CheckContent runs a post or comment through the content filter before it is saved.
+//
+// The configured banned words, link limit and repeated content rules run first, then the rules added with
+// UseContentRule. A rejected post or comment must not be saved, held content is saved hidden and both held
+// and flagged content is added to the moderation queue by ApplyContentResult.
+func CheckContent(ctx context.Context, db *gorm.DB, content contentfilter.Content) (contentfilter.Result, error) {
+	cfg := config.LoadContentFilterConfig()
+
+	pipeline := contentfilter.New(
+		contentfilter.BannedWords{Matcher: loadBannedWords(cfg), Action: parseFilterAction(cfg.BannedWordsAction, contentfilter.Reject)},
+		contentfilter.LinkLimit{Max: cfg.MaxLinks, Action: parseFilterAction(cfg.LinksAction, contentfilter.Hold)},
+		contentfilter.Duplicates{
+			History: contentHistory{db: db},
+			Limit:   cfg.DuplicateLimit,
+			Window:  cfg.DuplicateWindow,
+			Action:  parseFilterAction(cfg.DuplicateAction, contentfilter.Reject),
+		},
+	)
+	extraContentRulesMu.RLock()
+	for _, rule := range extraContentRules {
+		pipeline.Use(rule)
+	}
+	extraContentRulesMu.RUnlock()
+
+	return pipeline.Check(ctx, content)
+}
+
+// ApplyContentResult hides held content and adds held and flagged content to the moderation queue.
+// content.ID must be the ID of the saved post or comment.
+func ApplyContentResult(db *gorm.DB, content contentfilter.Content, result contentfilter.Result) error {
+	if result.Action != contentfilter.Hold && result.Action != contentfilter.Flag {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		action := ModerationFlag
+		if result.Action == contentfilter.Hold {
+			action = ModerationHold
+			if err := tx.Model(moderatedModel(content.Type)).
+				Where("id = ? AND moderation_status = ?", content.ID, Models.ModerationVisible).
+				Update("moderation_status", Models.ModerationHidden).Error; err != nil {
+				return err
+			}
+		}
+
+		var moderationCase Models.ModerationCase
+		if err := openCase(tx, &moderationCase, content.Type, content.ID, content.AuthorID); err != nil {
+			return err
+		}
+		return logModerationAction(tx, moderationCase.ID, 0, action, result.Reason())
+	})
+}
+
+// HideMutedWords returns a scope that leaves out the rows whose columns contain a word or phrase the
+// viewer muted. Muted words are matched in SQL, case insensitive and as whole words, so that pages and
+// their counts only include the content the viewer sees.
+func HideMutedWords(db *gorm.DB, viewerID uint, columns ...string) (func(db *gorm.DB) *gorm.DB, error) {
+	var words []string
+	if viewerID != 0 {
+		if err := db.Model(&Models.MutedWord{}).Where("user_id = ?", viewerID).Pluck("word", &words).Error; err != nil {
+			return nil, err
+		}
+	}
+	pattern := mutedWordsPattern(words)
+	return func(db *gorm.DB) *gorm.DB {
+		if pattern == "" {
+			return db
+		}
+		return db.Where("NOT (CONCAT_WS(' ', "+strings.Join(columns, ", ")+") REGEXP ?)", pattern)
+	}, nil
+}
+
+// mutedWordsPattern returns the regular expression that matches any of the words as a whole word, or an
+// empty string when there are none. The words of a phrase may be separated by any whitespace.
+func mutedWordsPattern(words []string) string {
+	var terms []string
+	for _, word := range words {
+		fields := strings.Fields(word)
+		if len(fields) == 0 {
+			continue
+		}
+		for i, field := range fields {
+			fields[i] = regexp.QuoteMeta(field)
+		}
+		terms = append(terms, strings.Join(fields, `\s+`))
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	return `(?i)(^|[^\p{L}\p{N}_])(` + strings.Join(terms, "|") + `)([^\p{L}\p{N}_]|$)`
+}
+
+// AddMutedWord mutes the word or phrase for the user.
+func AddMutedWord(db *gorm.DB, userID uint, word string) (*Models.MutedWord, error) {
+	word = strings.ToLower(strings.TrimSpace(word))
+
+	var count int64
+	if err := db.Model(&Models.MutedWord{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= maxMutedWords {
+		return nil, ErrMutedWordLimit
+	}
+	var existing int64
+	if err := db.Model(&Models.MutedWord{}).Where("user_id = ? AND word = ?", userID, word).Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, ErrMutedWordTaken
+	}
+
+	mutedWord := Models.MutedWord{UserID: userID, Word: word}
+	if err := db.Create(&mutedWord).Error; err != nil {
+		return nil, err
+	}
+	return &mutedWord, nil
+}
+
+// contentHistory finds earlier posts and comments with the same body.
+type contentHistory struct {
+	db *gorm.DB
+}
+
+// CountDuplicates implements contentfilter.History.
+func (h contentHistory) CountDuplicates(ctx context.Context, content contentfilter.Content, since time.Time) (int, error) {
+	var count int64
+	err := h.db.WithContext(ctx).Model(moderatedModel(content.Type)).
+		Where("user_id = ? AND body = ? AND created_at >= ? AND id <> ?", content.AuthorID, content.Body, since, content.ID).
+		Count(&count).Error
+	return int(count), err
+}
+
+// loadBannedWords returns the matcher of the configured banned words. The list is loaded on first use.
+func loadBannedWords(cfg *config.ContentFilterConfig) *contentfilter.Matcher {
+	bannedWordsOnce.Do(func() {
+		words := append([]string{}, cfg.BannedWords...)
+		if cfg.BannedWordsFile != "" {
+			fileWords, err := readWordList(cfg.BannedWordsFile)
+			if err != nil && !os.IsNotExist(err) {
+				log.Printf("failed to load banned words from %s: %v", cfg.BannedWordsFile, err)
+			}
+			words = append(words, fileWords...)
+		}
+		bannedWords = contentfilter.NewMatcher(words)
+	})
+	return bannedWords
+}
+
+// readWordList reads one word or phrase per line. Empty lines and lines starting with # are skipped.
+func readWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	return words, scanner.Err()
+}
+
+func parseFilterAction(value string, fallback contentfilter.Action) contentfilter.Action {
+	action, err := contentfilter.ParseAction(value)
+	if err != nil {
+		log.Println(err)
+		return fallback
+	}
+	return action
+}
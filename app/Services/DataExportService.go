@@ -0,0 +1,303 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gonga/app/Models"
+	"gonga/config"
+	"gonga/packages"
+	mail "gonga/packages/Mail"
+	queue "gonga/packages/Queue"
+	"gonga/utils"
+	"html"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// staleExportAge is how long an export may stay pending before it is considered lost, for example
+// because the server restarted while it was queued.
+const staleExportAge = 24 * time.Hour
+
+var (
+	ErrExportInProgress  = errors.New("an export of your data is already being prepared")
+	ErrInvalidExportLink = errors.New("this download link is invalid or has expired")
+)
+
+// RequestDataExport queues an export of the user's personal data. The user gets an email with a
+// download link once the archive is ready. Only one export per user can be in progress.
+func RequestDataExport(db *gorm.DB, user *Models.User) (*Models.DataExport, error) {
+	var inProgress int64
+	if err := db.Model(&Models.DataExport{}).
+		Where("user_id = ? AND status IN ?", user.ID, []Models.ExportStatus{Models.ExportPending, Models.ExportProcessing}).
+		Count(&inProgress).Error; err != nil {
+		return nil, err
+	}
+	if inProgress > 0 {
+		return nil, ErrExportInProgress
+	}
+
+	export := Models.DataExport{UserID: user.ID, Status: Models.ExportPending}
+	if err := db.Create(&export).Error; err != nil {
+		return nil, err
+	}
+
+	err := queue.Default().Dispatch("data-export", func(ctx context.Context) error {
+		return BuildDataExport(ctx, db, export.ID)
+	})
+	if err != nil {
+		db.Model(&export).Update("status", Models.ExportFailed)
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// BuildDataExport writes the ZIP archive of a requested export and emails the download link to the user.
+//
+// The archive holds one JSON file per kind of data. Rows are read from the database and written to the
+// archive one at a time, so the size of an account does not matter for memory use.
+func BuildDataExport(ctx context.Context, db *gorm.DB, exportID uint) error {
+	var export Models.DataExport
+	if err := db.First(&export, exportID).Error; err != nil {
+		return err
+	}
+	var user Models.User
+	if err := db.First(&user, export.UserID).Error; err != nil {
+		db.Model(&export).Update("status", Models.ExportFailed)
+		return err
+	}
+	if err := db.Model(&export).Update("status", Models.ExportProcessing).Error; err != nil {
+		return err
+	}
+
+	exportConfig := config.LoadExportConfig()
+	path := filepath.Join(exportConfig.Path, uuid.NewString()+".zip")
+	size, err := writeDataExport(ctx, db, &user, path)
+	if err != nil {
+		os.Remove(path)
+		db.Model(&export).Update("status", Models.ExportFailed)
+		return err
+	}
+
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		os.Remove(path)
+		db.Model(&export).Update("status", Models.ExportFailed)
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(exportConfig.LinkLifetime)
+	if err := db.Model(&export).Updates(map[string]interface{}{
+		"status":       Models.ExportReady,
+		"path":         path,
+		"size":         size,
+		"token":        hashExportToken(token),
+		"completed_at": now,
+		"expires_at":   expiresAt,
+	}).Error; err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	// Only the newest export is kept
+	if err := deleteDataExports(db, db.Where("user_id = ? AND id <> ? AND status = ?", user.ID, export.ID, Models.ExportReady)); err != nil {
+		log.Println("failed to delete previous data exports:", err)
+	}
+
+	return sendDataExportReady(user.Email, token, expiresAt)
+}
+
+// FindDataExport returns the ready export the download token was sent for.
+func FindDataExport(db *gorm.DB, token string) (*Models.DataExport, error) {
+	var export Models.DataExport
+	err := db.Where("token = ? AND status = ?", hashExportToken(token), Models.ExportReady).First(&export).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidExportLink
+		}
+		return nil, err
+	}
+	if export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return nil, ErrInvalidExportLink
+	}
+	return &export, nil
+}
+
+// PruneDataExports deletes expired exports with their archives and fails exports that were lost
+// before they could be built.
+func PruneDataExports(db *gorm.DB) error {
+	if err := db.Model(&Models.DataExport{}).
+		Where("status IN ? AND created_at < ?", []Models.ExportStatus{Models.ExportPending, Models.ExportProcessing}, time.Now().Add(-staleExportAge)).
+		Update("status", Models.ExportFailed).Error; err != nil {
+		return err
+	}
+	return deleteDataExports(db, db.Where("expires_at < ? OR status = ?", time.Now(), Models.ExportFailed))
+}
+
+// deleteDataExports deletes the exports matched by query and their archives.
+func deleteDataExports(db *gorm.DB, query *gorm.DB) error {
+	var exports []Models.DataExport
+	if err := query.Find(&exports).Error; err != nil {
+		return err
+	}
+	for _, export := range exports {
+		if export.Path != "" {
+			if err := os.Remove(export.Path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := db.Unscoped().Delete(&export).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportSection is a JSON file of the archive, filled with the rows of a query.
+type exportSection struct {
+	name   string
+	query  *gorm.DB
+	newRow func() interface{}
+}
+
+func writeDataExport(ctx context.Context, db *gorm.DB, user *Models.User, path string) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	profile, err := archive.Create("profile.json")
+	if err != nil {
+		return 0, err
+	}
+	if err := json.NewEncoder(profile).Encode(user); err != nil {
+		return 0, err
+	}
+
+	sections := []exportSection{
+		{"posts.json", db.Model(&Models.Post{}).Where("user_id = ?", user.ID), func() interface{} { return &Models.Post{} }},
+		{"comments.json", db.Model(&Models.Comment{}).Where("user_id = ?", user.ID), func() interface{} { return &Models.Comment{} }},
+		{"likes.json", db.Model(&Models.Like{}).Where("user_id = ?", user.ID), func() interface{} { return &Models.Like{} }},
+		{"following.json", db.Model(&Models.Follow{}).Where("follower_id = ?", user.ID), func() interface{} { return &Models.Follow{} }},
+		{"followers.json", db.Model(&Models.Follow{}).Where("following_id = ?", user.ID), func() interface{} { return &Models.Follow{} }},
+		{"blocks.json", db.Model(&Models.Block{}).Where("blocker_id = ?", user.ID), func() interface{} { return &Models.Block{} }},
+		{"mutes.json", db.Model(&Models.Mute{}).Where("muter_id = ?", user.ID), func() interface{} { return &Models.Mute{} }},
+		{"muted_words.json", db.Model(&Models.MutedWord{}).Where("user_id = ?", user.ID), func() interface{} { return &Models.MutedWord{} }},
+		{"reports.json", db.Model(&Models.Report{}).Where("reporter_id = ?", user.ID), func() interface{} { return &Models.Report{} }},
+		{"appeals.json", db.Model(&Models.Appeal{}).Where("user_id = ?", user.ID), func() interface{} { return &Models.Appeal{} }},
+		{"verifications.json", db.Model(&Models.VerificationEvent{}).Where("user_id = ?", user.ID), func() interface{} { return &Models.VerificationEvent{} }},
+		{"mentions.json", db.Model(&Models.Mention{}).Where("user_id = ?", user.ID), func() interface{} { return &Models.Mention{} }},
+		{"media.json", db.Model(&Models.Media{}).Where("user_id = ? OR (owner_type = ? AND owner_id = ?)", user.ID, "users", user.ID), func() interface{} { return &Models.Media{} }},
+		{"notifications.json", db.Model(&Models.Notification{}).Where("email = ?", user.Email), func() interface{} { return &Models.Notification{} }},
+	}
+	for _, section := range sections {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if err := writeExportSection(ctx, db, archive, section); err != nil {
+			return 0, fmt.Errorf("failed to export %s: %w", section.name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// writeExportSection writes the rows of the section as a JSON array, one row at a time.
+func writeExportSection(ctx context.Context, db *gorm.DB, archive *zip.Writer, section exportSection) error {
+	w, err := archive.Create(section.name)
+	if err != nil {
+		return err
+	}
+
+	rows, err := section.query.Order("id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	encoder := json.NewEncoder(w)
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for first := true; rows.Next(); first = false {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		row := section.newRow()
+		if err := db.ScanRows(rows, row); err != nil {
+			return err
+		}
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "]\n")
+	return err
+}
+
+func hashExportToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func sendDataExportReady(email, token string, expiresAt time.Time) error {
+	appConfig := config.LoadAppConfig()
+	downloadLink, err := packages.URL("exports.download", nil, "token", token)
+	if err != nil {
+		return err
+	}
+	expires := expiresAt.Format("January 2, 2006 15:04 MST")
+	textContent := fmt.Sprintf("The export of your %s data is ready. Download it here: %s\nThe link expires on %s.",
+		appConfig.Name, downloadLink, expires)
+	htmlContent := fmt.Sprintf("<p>The export of your %s data is ready. Click <a href=\"%s\">here</a> to download it.</p>"+
+		"<p>The link expires on %s.</p>",
+		html.EscapeString(appConfig.Name), html.EscapeString(downloadLink), expires)
+
+	readyEmail := &mail.Mailable{
+		To: []string{email},
+		Content: struct {
+			Subject string
+			Html    string
+			Text    string
+		}{
+			Subject: "Your data export is ready",
+			Text:    textContent,
+			Html:    htmlContent,
+		},
+	}
+
+	return readyEmail.Send()
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gonga/app/Models"
+	"gonga/config"
+	"gonga/packages"
+	mail "gonga/packages/Mail"
+	"gonga/utils"
+	"html"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// emailChangeLifetime is how long the confirmation link for a new email address is valid.
+const emailChangeLifetime = 24 * time.Hour
+
+var (
+	ErrEmailTaken         = errors.New("the email has already been taken")
+	ErrInvalidEmailChange = errors.New("this email confirmation link is invalid or has expired")
+	ErrEmailUnchanged     = errors.New("the new email is the same as the current one")
+)
+
+// RequestEmailChange starts changing the user's email address to newEmail.
+//
+// A confirmation link is sent to the new address and a notice to the current one. The address is
+// only switched by ConfirmEmailChange. A new request replaces a pending one.
+func RequestEmailChange(db *gorm.DB, user *Models.User, newEmail string) error {
+	newEmail = strings.TrimSpace(newEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return ErrEmailUnchanged
+	}
+	if utils.UserExistsWithEmail(db, newEmail) {
+		return ErrEmailTaken
+	}
+
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&Models.EmailChange{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Models.EmailChange{
+			UserID:    user.ID,
+			NewEmail:  newEmail,
+			Token:     hashEmailChangeToken(token),
+			ExpiresAt: time.Now().Add(emailChangeLifetime),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := sendEmailChangeConfirmation(newEmail, token); err != nil {
+		return err
+	}
+
+	go func(oldEmail string) {
+		if err := sendEmailChangeNotice(oldEmail, newEmail); err != nil {
+			log.Println("failed to send email change notice:", err)
+		}
+	}(user.Email)
+
+	return nil
+}
+
+// ConfirmEmailChange applies the pending email change the token was sent for and returns the updated user.
+// The token can only be used once, it is burned even when the change is rejected.
+func ConfirmEmailChange(db *gorm.DB, token string) (*Models.User, error) {
+	var change Models.EmailChange
+	if err := db.Where("token = ?", hashEmailChangeToken(token)).First(&change).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidEmailChange
+		}
+		return nil, err
+	}
+	// Delete the token in its own statement, so that expired and rejected tokens are burned too.
+	// Only the request that deletes it may use it.
+	result := db.Unscoped().Delete(&change)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected != 1 || time.Now().After(change.ExpiresAt) {
+		return nil, ErrInvalidEmailChange
+	}
+
+	var user Models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// The address may have been registered since the change was requested
+		if utils.UserExistsWithEmail(tx, change.NewEmail) {
+			return ErrEmailTaken
+		}
+
+		if err := tx.First(&user, change.UserID).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"email":          change.NewEmail,
+			"email_verified": true,
+		}).Error
+		if isDuplicateKey(err) {
+			return ErrEmailTaken
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func hashEmailChangeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func sendEmailChangeConfirmation(email, token string) error {
+	// The link opens a page of the frontend, which confirms the change with POST /me/email/confirm
+	confirmLink := packages.FrontendURL("/confirm-email", url.Values{"token": {token}})
+	textContent := fmt.Sprintf("Click on the following link to confirm your new email address: %s", confirmLink)
+	htmlContent := fmt.Sprintf("<p>Click <a href=\"%s\">here</a> to confirm your new email address</p>", html.EscapeString(confirmLink))
+
+	confirmEmail := &mail.Mailable{
+		To: []string{email},
+		Content: struct {
+			Subject string
+			Html    string
+			Text    string
+		}{
+			Subject: "Confirm your new email address",
+			Text:    textContent,
+			Html:    htmlContent,
+		},
+	}
+
+	return confirmEmail.Send()
+}
+
+func sendEmailChangeNotice(oldEmail, newEmail string) error {
+	appConfig := config.LoadAppConfig()
+	textContent := fmt.Sprintf("A change of the email address of your %s account to %s was requested. "+
+		"If this was not you, change your password right away.", appConfig.Name, newEmail)
+	htmlContent := fmt.Sprintf("<p>A change of the email address of your %s account to <strong>%s</strong> was requested.</p>"+
+		"<p>If this was not you, change your password right away.</p>",
+		html.EscapeString(appConfig.Name), html.EscapeString(newEmail))
+
+	noticeEmail := &mail.Mailable{
+		To: []string{oldEmail},
+		Content: struct {
+			Subject string
+			Html    string
+			Text    string
+		}{
+			Subject: "Email address change requested",
+			Text:    textContent,
+			Html:    htmlContent,
+		},
+	}
+
+	return noticeEmail.Send()
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"gonga/app/Models"
+	idempotency "gonga/packages/Idempotency"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyStore is an idempotency.Store that keeps the keys in the idempotency_keys table, so they
+// are shared by every server and survive restarts.
+type IdempotencyStore struct {
+	DB *gorm.DB
+}
+
+// Reserve claims the key for the request with the hash until the lease passes.
+func (s IdempotencyStore) Reserve(key, requestHash string, lease time.Duration) (*idempotency.Record, error) {
+	now := time.Now()
+
+	// An expired key, or the lease of a request that never completed, can be claimed again
+	if err := s.DB.Where("hash = ? AND expires_at <= ?", key, now).Delete(&Models.IdempotencyKey{}).Error; err != nil {
+		return nil, err
+	}
+
+	// The unique index on the hash makes sure only one request claims the key
+	createErr := s.DB.Create(&Models.IdempotencyKey{Hash: key, RequestHash: requestHash, ExpiresAt: now.Add(lease)}).Error
+	if createErr == nil {
+		return nil, nil
+	}
+
+	var existing Models.IdempotencyKey
+	if err := s.DB.Where("hash = ?", key).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, createErr
+		}
+		return nil, err
+	}
+
+	record := &idempotency.Record{
+		RequestHash: existing.RequestHash,
+		Completed:   existing.Completed,
+		Response:    idempotency.Response{Status: existing.Status, Header: http.Header{}, Body: existing.Body},
+	}
+	if existing.Header != "" {
+		if err := json.Unmarshal([]byte(existing.Header), &record.Response.Header); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+// Complete stores the response of the request that claimed the key until ttl passes.
+func (s IdempotencyStore) Complete(key string, response idempotency.Response, ttl time.Duration) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+	return s.DB.Model(&Models.IdempotencyKey{}).Where("hash = ?", key).Updates(map[string]interface{}{
+		"completed":  true,
+		"status":     response.Status,
+		"header":     string(header),
+		"body":       response.Body,
+		"expires_at": time.Now().Add(ttl),
+	}).Error
+}
+
+// Release gives the key up so the request can be retried.
+func (s IdempotencyStore) Release(key string) error {
+	return s.DB.Where("hash = ?", key).Delete(&Models.IdempotencyKey{}).Error
+}
+
+// PruneIdempotencyKeys deletes the expired idempotency keys.
+func PruneIdempotencyKeys(db *gorm.DB) error {
+	return db.Where("expires_at <= ?", time.Now()).Delete(&Models.IdempotencyKey{}).Error
+}
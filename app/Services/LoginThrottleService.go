@@ -0,0 +1,204 @@
+package services
+
+import (
+	"fmt"
+	"gonga/app/Models"
+	"gonga/config"
+	mail "gonga/packages/Mail"
+	"gonga/utils"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// pendingLogin is the reason of an attempt whose credentials are still being checked.
+const pendingLogin = "pending"
+
+// ReserveLoginAttempt records a login attempt before its credentials are checked and returns how long
+// the client has to wait before it may try to log in as username again. A zero duration means the
+// attempt is allowed; a refused attempt is not recorded.
+//
+// Until it is finished, the reserved attempt counts as a failure, so parallel guesses throttle each
+// other instead of all passing the check before the first failure is recorded. Every allowed attempt
+// has to end with FinishLoginAttempt or ReleaseLoginAttempt.
+func ReserveLoginAttempt(db *gorm.DB, cfg config.LockoutConfig, r *http.Request, username string) (*Models.LoginAttempt, time.Duration, error) {
+	attempt := Models.LoginAttempt{
+		Username:  normalizeUsername(username),
+		IPAddress: utils.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Reason:    pendingLogin,
+	}
+	if err := db.Create(&attempt).Error; err != nil {
+		return nil, 0, err
+	}
+
+	wait, err := loginRetryAfter(db, cfg, &attempt)
+	if err != nil || wait > 0 {
+		if err := db.Unscoped().Delete(&attempt).Error; err != nil {
+			log.Println("failed to release login attempt:", err)
+		}
+		return nil, wait, err
+	}
+	return &attempt, 0, nil
+}
+
+// loginRetryAfter returns how long the attempt has to wait because of the attempts reserved before it.
+//
+// Failures are counted per username, since the user's last successful login, and per IP address.
+// The longer of the two waits wins.
+func loginRetryAfter(db *gorm.DB, cfg config.LockoutConfig, attempt *Models.LoginAttempt) (time.Duration, error) {
+	now := time.Now()
+	since := now.Add(-cfg.Window)
+
+	userSince, err := lastSuccessSince(db, attempt.Username, since)
+	if err != nil {
+		return 0, err
+	}
+	userFailures, userLast, err := countFailures(db.Where("id < ? AND username = ?", attempt.ID, attempt.Username), userSince)
+	if err != nil {
+		return 0, err
+	}
+	ipFailures, ipLast, err := countFailures(db.Where("id < ? AND ip_address = ?", attempt.ID, attempt.IPAddress), since)
+	if err != nil {
+		return 0, err
+	}
+
+	wait := backoff(cfg, userFailures, cfg.FreeAttempts, cfg.LockoutAttempts, userLast, now)
+	if ipWait := backoff(cfg, ipFailures, cfg.IPFreeAttempts, cfg.IPLockoutAttempts, ipLast, now); ipWait > wait {
+		wait = ipWait
+	}
+	return wait, nil
+}
+
+// FinishLoginAttempt stores the outcome of a reserved login attempt for auditing and throttling.
+//
+// When a failure locks the account, the owner gets a "suspicious login" email.
+func FinishLoginAttempt(db *gorm.DB, cfg config.LockoutConfig, attempt *Models.LoginAttempt, user *Models.User, successful bool, reason string) error {
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	attempt.Successful = successful
+	attempt.Reason = reason
+	err := db.Model(attempt).Updates(map[string]interface{}{"user_id": attempt.UserID, "successful": successful, "reason": reason}).Error
+	if err != nil {
+		return err
+	}
+
+	if successful || user == nil {
+		return nil
+	}
+
+	// Only notify once, for the failure that starts the lockout. Failures are counted up to this
+	// attempt, so that concurrent failures don't both, or neither, see the threshold.
+	since, err := lastSuccessSince(db, attempt.Username, time.Now().Add(-cfg.Window))
+	if err != nil {
+		return err
+	}
+	failures, _, err := countFailures(db.Where("username = ? AND id <= ?", attempt.Username, attempt.ID), since)
+	if err != nil {
+		return err
+	}
+	if failures == int64(cfg.LockoutAttempts) {
+		go func(email, ip, userAgent string) {
+			if err := sendSuspiciousLoginEmail(email, ip, userAgent, cfg.LockoutDuration); err != nil {
+				log.Println("failed to send suspicious login email:", err)
+			}
+		}(user.Email, attempt.IPAddress, attempt.UserAgent)
+	}
+
+	return nil
+}
+
+// ReleaseLoginAttempt deletes a reserved attempt that neither failed nor finished a login, like a
+// correct password that still needs a two-factor code. Finished attempts are kept.
+func ReleaseLoginAttempt(db *gorm.DB, attempt *Models.LoginAttempt) {
+	err := db.Unscoped().Where("id = ? AND reason = ?", attempt.ID, pendingLogin).Delete(&Models.LoginAttempt{}).Error
+	if err != nil {
+		log.Println("failed to release login attempt:", err)
+	}
+}
+
+// lastSuccessSince returns when the username last logged in successfully after since, or since.
+func lastSuccessSince(db *gorm.DB, username string, since time.Time) (time.Time, error) {
+	var lastSuccess Models.LoginAttempt
+	err := db.Where("username = ? AND successful = ? AND created_at > ?", username, true, since).
+		Order("created_at desc").Limit(1).Find(&lastSuccess).Error
+	if err != nil || lastSuccess.ID == 0 {
+		return since, err
+	}
+	return lastSuccess.CreatedAt, nil
+}
+
+func countFailures(scope *gorm.DB, since time.Time) (int64, time.Time, error) {
+	var result struct {
+		Failures    int64
+		LastFailure *time.Time
+	}
+	err := scope.Model(&Models.LoginAttempt{}).
+		Select("COUNT(*) AS failures, MAX(created_at) AS last_failure").
+		Where("successful = ? AND created_at > ?", false, since).
+		Scan(&result).Error
+	if err != nil || result.LastFailure == nil {
+		return 0, time.Time{}, err
+	}
+	return result.Failures, *result.LastFailure, nil
+}
+
+// backoff returns the remaining wait for the given number of failures, doubling the delay for
+// every failure past the free attempts and switching to a fixed lockout at the lockout threshold.
+func backoff(cfg config.LockoutConfig, failures int64, free, lockout int, lastFailure, now time.Time) time.Duration {
+	var until time.Time
+	switch {
+	case failures >= int64(lockout):
+		until = lastFailure.Add(cfg.LockoutDuration)
+	case failures >= int64(free):
+		delay := cfg.BaseDelay
+		for i := int64(free); i < failures && delay < cfg.MaxDelay; i++ {
+			delay *= 2
+		}
+		if delay > cfg.MaxDelay {
+			delay = cfg.MaxDelay
+		}
+		until = lastFailure.Add(delay)
+	default:
+		return 0
+	}
+
+	if wait := until.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func sendSuspiciousLoginEmail(email, ip, userAgent string, lockout time.Duration) error {
+	appConfig := config.LoadAppConfig()
+	textContent := fmt.Sprintf("We blocked several failed attempts to sign in to your %s account from %s (%s). "+
+		"Sign-in is locked for %s. If this was not you, we recommend changing your password.",
+		appConfig.Name, ip, userAgent, lockout)
+	htmlContent := fmt.Sprintf("<p>We blocked several failed attempts to sign in to your %s account from <strong>%s</strong> (%s).</p>"+
+		"<p>Sign-in is locked for %s. If this was not you, we recommend changing your password.</p>",
+		html.EscapeString(appConfig.Name), html.EscapeString(ip), html.EscapeString(userAgent), lockout)
+
+	suspiciousEmail := &mail.Mailable{
+		To: []string{email},
+		Content: struct {
+			Subject string
+			Html    string
+			Text    string
+		}{
+			Subject: "Suspicious login attempts",
+			Text:    textContent,
+			Html:    htmlContent,
+		},
+	}
+
+	return suspiciousEmail.Send()
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"gonga/app/Models"
+	"gonga/config"
+	mail "gonga/packages/Mail"
+	"html"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Actions of the moderation audit log besides the resolutions.
+const (
+	ModerationClaim   = "claim"
+	ModerationRelease = "release"
+	ModerationHide    = "hide"
+	ModerationHold    = "hold"
+	ModerationFlag    = "flag"
+)
+
+var (
+	ErrReportSubjectNotFound = errors.New("the reported content does not exist")
+	ErrReportOwnContent      = errors.New("you cannot report your own content")
+	ErrAlreadyReported       = errors.New("you already reported this")
+	ErrCaseResolved          = errors.New("this report has already been resolved")
+	ErrCaseClaimed           = errors.New("this report is claimed by another moderator")
+	ErrInvalidResolution     = errors.New("this action is not available for the reported content")
+)
+
+// ReportSubject files the user's report of a post, comment or user. The report is added to the open
+// moderation case of the subject, or opens a new one. Posts and comments that reach the auto-hide
+// threshold of reports are hidden until a moderator resolves the case.
+func ReportSubject(db *gorm.DB, reporterID uint, subjectType string, subjectID uint, reason Models.ReportReason, details string) (*Models.ModerationCase, error) {
+	subjectUserID, err := reportSubjectUserID(db, subjectType, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	if subjectUserID == reporterID {
+		return nil, ErrReportOwnContent
+	}
+
+	threshold := config.LoadModerationConfig().AutoHideThreshold
+
+	var moderationCase Models.ModerationCase
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := openCase(tx, &moderationCase, subjectType, subjectID, subjectUserID); err != nil {
+			return err
+		}
+
+		var reported int64
+		if err := tx.Model(&Models.Report{}).
+			Where("case_id = ? AND reporter_id = ?", moderationCase.ID, reporterID).
+			Count(&reported).Error; err != nil {
+			return err
+		}
+		if reported > 0 {
+			return ErrAlreadyReported
+		}
+
+		// The count doesn't see a concurrent report of the same reporter, the unique index does
+		report := Models.Report{CaseID: moderationCase.ID, ReporterID: reporterID, Reason: reason, Details: details}
+		if err := tx.Create(&report).Error; err != nil {
+			if isDuplicateKey(err) {
+				return ErrAlreadyReported
+			}
+			return err
+		}
+		if err := tx.Model(&moderationCase).UpdateColumn("report_count", gorm.Expr("report_count + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&moderationCase, moderationCase.ID).Error; err != nil {
+			return err
+		}
+
+		if threshold <= 0 || moderationCase.ReportCount < threshold || subjectType == "users" {
+			return nil
+		}
+		result := tx.Model(moderatedModel(subjectType)).
+			Where("id = ? AND moderation_status = ?", subjectID, Models.ModerationVisible).
+			Update("moderation_status", Models.ModerationHidden)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return logModerationAction(tx, moderationCase.ID, 0, ModerationHide,
+			fmt.Sprintf("hidden after %d reports", moderationCase.ReportCount))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &moderationCase, nil
+}
+
+// ClaimCase assigns the open case to the moderator, so other moderators know it is being handled.
+func ClaimCase(db *gorm.DB, moderationCase *Models.ModerationCase, moderatorID uint) error {
+	if moderationCase.Status != Models.CaseOpen {
+		return ErrCaseResolved
+	}
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(moderationCase).
+			Where("status = ? AND (assignee_id IS NULL OR assignee_id = ?)", Models.CaseOpen, moderatorID).
+			Updates(map[string]interface{}{"assignee_id": moderatorID, "claimed_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCaseClaimed
+		}
+		moderationCase.AssigneeID = &moderatorID
+		moderationCase.ClaimedAt = &now
+		return logModerationAction(tx, moderationCase.ID, moderatorID, ModerationClaim, "")
+	})
+}
+
+// ReleaseCase returns the case claimed by the moderator to the queue.
+func ReleaseCase(db *gorm.DB, moderationCase *Models.ModerationCase, moderatorID uint) error {
+	if moderationCase.Status != Models.CaseOpen {
+		return ErrCaseResolved
+	}
+	if moderationCase.AssigneeID == nil || *moderationCase.AssigneeID != moderatorID {
+		return ErrCaseClaimed
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(moderationCase).Updates(map[string]interface{}{"assignee_id": nil, "claimed_at": nil}).Error; err != nil {
+			return err
+		}
+		moderationCase.AssigneeID = nil
+		moderationCase.ClaimedAt = nil
+		return logModerationAction(tx, moderationCase.ID, moderatorID, ModerationRelease, "")
+	})
+}
+
+// ResolveCase closes the case with the moderator's decision about the subject user's content:
+//
+//   - dismiss keeps the content and shows it again if reports hid it
+//   - remove removes the post or comment, it is shown as a tombstone
+//   - warn emails the note to the subject user as a warning
+//   - suspend suspends the subject user with the note as the reason
+//
+// Appeals are either dismissed, which keeps the account state, or resolved with reinstate, which makes
+// the account active again. The user is emailed about the decision.
+//
+// The moderator has to be the assignee, unless nobody claimed the case.
+func ResolveCase(db *gorm.DB, moderationCase *Models.ModerationCase, moderatorID uint, subjectUser *Models.User, resolution Models.Resolution, note string) error {
+	if moderationCase.Status != Models.CaseOpen {
+		return ErrCaseResolved
+	}
+	if moderationCase.AssigneeID != nil && *moderationCase.AssigneeID != moderatorID {
+		return ErrCaseClaimed
+	}
+	if !isValidResolution(moderationCase.SubjectType, resolution) {
+		return ErrInvalidResolution
+	}
+	if resolution != Models.ResolutionDismiss && resolution != Models.ResolutionRemove && subjectUser == nil {
+		return ErrInvalidResolution
+	}
+
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Another moderator may have resolved or claimed the case in the meantime
+		result := tx.Model(moderationCase).
+			Where("status = ? AND (assignee_id IS NULL OR assignee_id = ?)", Models.CaseOpen, moderatorID).
+			Updates(map[string]interface{}{
+				"status":         Models.CaseResolved,
+				"open":           nil,
+				"resolution":     resolution,
+				"resolved_by_id": moderatorID,
+				"resolved_at":    now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCaseClaimed
+		}
+
+		switch resolution {
+		case Models.ResolutionDismiss:
+			if moderationCase.SubjectType == "posts" || moderationCase.SubjectType == "comments" {
+				if err := tx.Model(moderatedModel(moderationCase.SubjectType)).
+					Where("id = ? AND moderation_status = ?", moderationCase.SubjectID, Models.ModerationHidden).
+					Update("moderation_status", Models.ModerationVisible).Error; err != nil {
+					return err
+				}
+			}
+		case Models.ResolutionRemove:
+			if err := tx.Model(moderatedModel(moderationCase.SubjectType)).
+				Where("id = ?", moderationCase.SubjectID).
+				Update("moderation_status", Models.ModerationRemoved).Error; err != nil {
+				return err
+			}
+		case Models.ResolutionWarn:
+			// The warning is sent once the case is resolved
+		case Models.ResolutionSuspend:
+			reason := note
+			if reason == "" {
+				reason = "violation of the community rules"
+			}
+			if err := setAccountState(tx, subjectUser, Models.AccountSuspended, reason, nil); err != nil {
+				return err
+			}
+		case Models.ResolutionReinstate:
+			if err := setAccountState(tx, subjectUser, Models.AccountActive, "", nil); err != nil {
+				return err
+			}
+		default:
+			return ErrInvalidResolution
+		}
+
+		return logModerationAction(tx, moderationCase.ID, moderatorID, string(resolution), note)
+	})
+	if err != nil {
+		return err
+	}
+
+	moderationCase.Status = Models.CaseResolved
+	moderationCase.Open = nil
+	moderationCase.Resolution = resolution
+	moderationCase.ResolvedByID = &moderatorID
+	moderationCase.ResolvedAt = &now
+
+	switch {
+	case resolution == Models.ResolutionWarn:
+		go func(email, subjectType, note string) {
+			if err := sendModerationWarning(email, subjectType, note); err != nil {
+				log.Println("failed to send moderation warning:", err)
+			}
+		}(subjectUser.Email, moderationCase.SubjectType, note)
+	case resolution == Models.ResolutionSuspend || resolution == Models.ResolutionReinstate:
+		notifyAccountState(*subjectUser)
+	case moderationCase.SubjectType == "appeals" && subjectUser != nil:
+		go func(email, note string) {
+			if err := sendAppealRejected(email, note); err != nil {
+				log.Println("failed to send appeal decision:", err)
+			}
+		}(subjectUser.Email, note)
+	}
+	return nil
+}
+
+// isValidResolution reports whether the resolution is available for cases about the subject type.
+func isValidResolution(subjectType string, resolution Models.Resolution) bool {
+	switch resolution {
+	case Models.ResolutionDismiss:
+		return true
+	case Models.ResolutionRemove:
+		return subjectType == "posts" || subjectType == "comments"
+	case Models.ResolutionWarn, Models.ResolutionSuspend:
+		return subjectType != "appeals"
+	case Models.ResolutionReinstate:
+		return subjectType == "appeals"
+	default:
+		return false
+	}
+}
+
+// openCase loads the open case of the subject into moderationCase, or opens a new one. When a concurrent
+// request opened the case first, the unique index on the open case of a subject refuses the second one
+// and the case of the other request is loaded instead.
+func openCase(db *gorm.DB, moderationCase *Models.ModerationCase, subjectType string, subjectID, subjectUserID uint) error {
+	open := true
+	err := db.Where("subject_type = ? AND subject_id = ? AND status = ?", subjectType, subjectID, Models.CaseOpen).
+		Attrs(Models.ModerationCase{
+			SubjectType:   subjectType,
+			SubjectID:     subjectID,
+			SubjectUserID: subjectUserID,
+			Status:        Models.CaseOpen,
+			Open:          &open,
+		}).
+		FirstOrCreate(moderationCase).Error
+	if !isDuplicateKey(err) {
+		return err
+	}
+	// A locking read sees the case the other transaction committed, a plain read of this transaction
+	// might not
+	*moderationCase = Models.ModerationCase{}
+	return db.Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("subject_type = ? AND subject_id = ? AND status = ?", subjectType, subjectID, Models.CaseOpen).
+		First(moderationCase).Error
+}
+
+// reportSubjectUserID returns the author of the reported post or comment, or the ID of the reported user.
+func reportSubjectUserID(db *gorm.DB, subjectType string, subjectID uint) (uint, error) {
+	var userIDs []uint
+	var err error
+	switch subjectType {
+	case "posts", "comments":
+		err = db.Model(moderatedModel(subjectType)).Where("id = ?", subjectID).Pluck("user_id", &userIDs).Error
+	case "users":
+		err = db.Model(&Models.User{}).Where("id = ?", subjectID).Pluck("id", &userIDs).Error
+	default:
+		return 0, ErrReportSubjectNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(userIDs) == 0 {
+		return 0, ErrReportSubjectNotFound
+	}
+	return userIDs[0], nil
+}
+
+// moderatedModel returns the model of a subject type that has a moderation status.
+func moderatedModel(subjectType string) interface{} {
+	if subjectType == "comments" {
+		return &Models.Comment{}
+	}
+	return &Models.Post{}
+}
+
+func logModerationAction(db *gorm.DB, caseID, moderatorID uint, action, note string) error {
+	return db.Create(&Models.ModerationAction{
+		CaseID:      caseID,
+		ModeratorID: moderatorID,
+		Action:      action,
+		Note:        note,
+	}).Error
+}
+
+func sendModerationWarning(email, subjectType, note string) error {
+	appConfig := config.LoadAppConfig()
+	subject := map[string]string{"posts": "one of your posts", "comments": "one of your comments"}[subjectType]
+	if subject == "" {
+		subject = "your account"
+	}
+	textContent := fmt.Sprintf("Our moderators reviewed a report about %s on %s and found that it breaks the community rules.\n\n%s\n\n"+
+		"Further violations may lead to the suspension of your account.", subject, appConfig.Name, note)
+	htmlContent := fmt.Sprintf("<p>Our moderators reviewed a report about %s on %s and found that it breaks the community rules.</p>"+
+		"<p>%s</p><p>Further violations may lead to the suspension of your account.</p>",
+		subject, html.EscapeString(appConfig.Name), html.EscapeString(note))
+
+	warningEmail := &mail.Mailable{
+		To: []string{email},
+		Content: struct {
+			Subject string
+			Html    string
+			Text    string
+		}{
+			Subject: "A warning about your content",
+			Text:    textContent,
+			Html:    htmlContent,
+		},
+	}
+
+	return warningEmail.Send()
+}
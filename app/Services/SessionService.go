@@ -0,0 +1,115 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"gonga/app/Models"
+	auth "gonga/packages/Auth"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sessionTouchInterval limits how often last_used_at is written for a session.
+const sessionTouchInterval = time.Minute
+
+// sessionNameLength is the maximum length of the stored session name (the client's user agent).
+const sessionNameLength = 255
+
+// IssueAccessToken starts a new session for the user and returns its access token.
+//
+// Only the hash of the session ID is stored, a leaked database can't be used to forge sessions.
+func IssueAccessToken(db *gorm.DB, userID uint, r *http.Request) (string, error) {
+	sessionID := uuid.NewString()
+
+	name := r.UserAgent()
+	if len(name) > sessionNameLength {
+		name = name[:sessionNameLength]
+	}
+	now := time.Now()
+	session := Models.PersonalAccessToken{
+		UserID:     userID,
+		Name:       name,
+		Token:      hashSessionID(sessionID),
+		Scopes:     "*",
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(auth.AccessTokenLifetime),
+	}
+	if err := db.Create(&session).Error; err != nil {
+		return "", err
+	}
+
+	return auth.GenerateToken(userID, sessionID)
+}
+
+// Session is an active session as seen by the AuthMiddleware.
+type Session struct {
+	ID         string
+	UserID     uint
+	Scopes     []string
+	Role       Models.Role
+	State      Models.AccountState
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
+
+// FindSession returns the session when it exists, belongs to the user and has not expired.
+// The user's current role and account state are loaded along with it.
+func FindSession(db *gorm.DB, userID uint, sessionID string) (*Session, bool) {
+	if sessionID == "" {
+		return nil, false
+	}
+
+	var row struct {
+		ID             uint
+		Scopes         string
+		Role           Models.Role
+		State          Models.AccountState
+		StateExpiresAt *time.Time
+		LastUsedAt     time.Time
+		ExpiresAt      time.Time
+	}
+	err := db.Table("personal_access_tokens").
+		Select("personal_access_tokens.id, personal_access_tokens.scopes, personal_access_tokens.last_used_at, personal_access_tokens.expires_at, users.role, users.state, users.state_expires_at").
+		Joins("JOIN users ON users.id = personal_access_tokens.user_id AND users.deleted_at IS NULL").
+		Where("personal_access_tokens.token = ? AND personal_access_tokens.user_id = ? AND personal_access_tokens.deleted_at IS NULL",
+			hashSessionID(sessionID), userID).
+		Limit(1).Scan(&row).Error
+	if err != nil || row.ID == 0 || time.Now().After(row.ExpiresAt) {
+		return nil, false
+	}
+
+	if time.Since(row.LastUsedAt) > sessionTouchInterval {
+		db.Model(&Models.PersonalAccessToken{}).Where("id = ?", row.ID).UpdateColumn("last_used_at", time.Now())
+	}
+
+	return &Session{
+		ID:         sessionID,
+		UserID:     userID,
+		Scopes:     strings.Fields(row.Scopes),
+		Role:       row.Role,
+		State:      Models.User{State: row.State, StateExpiresAt: row.StateExpiresAt}.CurrentState(),
+		LastUsedAt: row.LastUsedAt,
+		ExpiresAt:  row.ExpiresAt,
+	}, true
+}
+
+// RevokeSession ends a single session, its access token is rejected from now on.
+func RevokeSession(db *gorm.DB, sessionID string) error {
+	return db.Unscoped().Where("token = ?", hashSessionID(sessionID)).Delete(&Models.PersonalAccessToken{}).Error
+}
+
+// RevokeOtherSessions ends every session of the user except the given one.
+func RevokeOtherSessions(db *gorm.DB, userID uint, keepSessionID string) error {
+	return db.Unscoped().
+		Where("user_id = ? AND token <> ?", userID, hashSessionID(keepSessionID)).
+		Delete(&Models.PersonalAccessToken{}).Error
+}
+
+func hashSessionID(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"gonga/app/Models"
+	oauth "gonga/packages/OAuth"
+	"gonga/utils"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnverifiedEmail = errors.New("the provider did not verify the email address of this account")
+	// ErrUnverifiedAccount is returned when the email belongs to a local account whose address was never
+	// confirmed, whoever registered it may not own the address.
+	ErrUnverifiedAccount = errors.New("an account with this email address exists, log in with its password and confirm the email address to sign in with this provider")
+)
+
+// OAuthStateStore keeps the pending social logins in the oauth_states table.
+type OAuthStateStore struct {
+	DB *gorm.DB
+}
+
+func (s OAuthStateStore) Save(login oauth.PendingLogin) error {
+	return s.DB.Create(&Models.OAuthState{
+		State:        login.State,
+		Provider:     login.Provider,
+		CodeVerifier: login.CodeVerifier,
+		Nonce:        login.Nonce,
+		ExpiresAt:    login.ExpiresAt,
+	}).Error
+}
+
+// Consume deletes the state and returns it. Of concurrent callbacks with the same state only the one
+// that deleted the row gets it.
+func (s OAuthStateStore) Consume(provider, state string) (*oauth.PendingLogin, error) {
+	var row Models.OAuthState
+	if err := s.DB.Where("state = ? AND provider = ?", state, provider).First(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, oauth.ErrInvalidState
+		}
+		return nil, err
+	}
+	result := s.DB.Unscoped().Where("id = ?", row.ID).Delete(&Models.OAuthState{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return nil, oauth.ErrInvalidState
+	}
+	return &oauth.PendingLogin{
+		State:        row.State,
+		Provider:     row.Provider,
+		CodeVerifier: row.CodeVerifier,
+		Nonce:        row.Nonce,
+		ExpiresAt:    row.ExpiresAt,
+	}, nil
+}
+
+var usernameUnsafeChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// ResolveProviderIdentity returns the user that belongs to an external identity.
+//
+// An already linked identity logs in its user. Otherwise the identity is linked to the existing user
+// with the same email address, or a new user is created. Both require the provider to have verified
+// the email address, so nobody can take over an account by registering its email at a provider.
+// Linking also requires the local account to have confirmed its email address, so nobody can register
+// someone else's email with a password and wait for the owner to sign in with a provider.
+func ResolveProviderIdentity(db *gorm.DB, provider string, identity *oauth.Identity) (*Models.User, error) {
+	var user Models.User
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var linked Models.ProviderIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, identity.Subject).First(&linked).Error
+		if err == nil {
+			return tx.First(&user, linked.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		email, ok := identity.LinkableEmail()
+		if !ok {
+			return ErrUnverifiedEmail
+		}
+
+		err = tx.Where("LOWER(email) = ?", email).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			user, err = newUserFromIdentity(tx, identity)
+		} else if err == nil && !user.EmailVerified {
+			return ErrUnverifiedAccount
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&Models.ProviderIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func newUserFromIdentity(db *gorm.DB, identity *oauth.Identity) (Models.User, error) {
+	username, err := uniqueUsername(db, strings.Split(identity.Email, "@")[0])
+	if err != nil {
+		return Models.User{}, err
+	}
+
+	// Social accounts get an unusable random password, they can set one through the password reset flow
+	randomPassword, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return Models.User{}, err
+	}
+	hashedPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return Models.User{}, err
+	}
+
+	firstName, lastName := identity.Name, ""
+	if parts := strings.SplitN(identity.Name, " ", 2); len(parts) == 2 {
+		firstName, lastName = parts[0], parts[1]
+	}
+
+	user := Models.User{
+		Username:      username,
+		Email:         identity.Email,
+		Password:      hashedPassword,
+		FirstName:     firstName,
+		LastName:      lastName,
+		AvatarURL:     identity.Picture,
+		EmailVerified: true,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		return Models.User{}, err
+	}
+	return user, nil
+}
+
+func uniqueUsername(db *gorm.DB, base string) (string, error) {
+	base = usernameUnsafeChars.ReplaceAllString(strings.ToLower(base), "")
+	if len(base) < 3 {
+		base = "user" + base
+	}
+	if len(base) > 20 {
+		base = base[:20]
+	}
+
+	username := base
+	for i := 1; i <= 100; i++ {
+		var count int64
+		if err := db.Unscoped().Model(&Models.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return username, nil
+		}
+		username = fmt.Sprintf("%s%d", base, i)
+	}
+	return "", errors.New("could not generate a unique username")
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"gonga/app/Models"
+	"gonga/config"
+	mail "gonga/packages/Mail"
+	"html"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNothingToAppeal = errors.New("your account is in good standing, there is nothing to appeal")
+	ErrAppealPending   = errors.New("your appeal is already being reviewed")
+)
+
+// ChangeAccountState sets the state of the user's account and emails the user about the change.
+//
+// expiresAt is when the account becomes active again, nil keeps the state until it is changed.
+// Suspended and banned users are logged out everywhere and can't log in, banned users' content is
+// hidden as well. Restricted users can log in and read, but can't post or interact with other users.
+func ChangeAccountState(db *gorm.DB, user *Models.User, state Models.AccountState, reason string, expiresAt *time.Time) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return setAccountState(tx, user, state, reason, expiresAt)
+	})
+	if err != nil {
+		return err
+	}
+	notifyAccountState(*user)
+	return nil
+}
+
+// SuspendUser suspends the user and logs them out everywhere. Suspended users can't log in
+// and lose every permission of their role until the suspension expires or is lifted.
+func SuspendUser(db *gorm.DB, user *Models.User, reason string, expiresAt *time.Time) error {
+	return ChangeAccountState(db, user, Models.AccountSuspended, reason, expiresAt)
+}
+
+// UnsuspendUser makes the user's account active again.
+func UnsuspendUser(db *gorm.DB, user *Models.User) error {
+	return ChangeAccountState(db, user, Models.AccountActive, "", nil)
+}
+
+// LiftExpiredAccountStates makes the accounts whose state expired active again and returns how many
+// accounts were reactivated.
+func LiftExpiredAccountStates(db *gorm.DB) (int, error) {
+	var users []Models.User
+	if err := db.Where("state <> ? AND state_expires_at <= ?", Models.AccountActive, time.Now()).Find(&users).Error; err != nil {
+		return 0, err
+	}
+
+	lifted := 0
+	for i := range users {
+		if err := setAccountState(db, &users[i], Models.AccountActive, "", nil); err != nil {
+			return lifted, err
+		}
+		notifyAccountState(users[i])
+		lifted++
+	}
+	return lifted, nil
+}
+
+// DeleteUser soft deletes the user and ends all of their sessions.
+func DeleteUser(db *gorm.DB, user *Models.User) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := RevokeOtherSessions(tx, user.ID, ""); err != nil {
+			return err
+		}
+		return tx.Delete(user).Error
+	})
+}
+
+// FileAppeal adds the user's appeal of their account state to the moderation queue. A moderator
+// either reinstates the account or dismisses the appeal, the user is emailed about the decision.
+func FileAppeal(db *gorm.DB, user *Models.User, message string) (*Models.Appeal, error) {
+	state := user.CurrentState()
+	if state == Models.AccountActive {
+		return nil, ErrNothingToAppeal
+	}
+
+	appeal := Models.Appeal{UserID: user.ID, State: state, Reason: user.StateReason, Message: message}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var pending int64
+		if err := tx.Model(&Models.ModerationCase{}).
+			Where("subject_type = ? AND subject_user_id = ? AND status = ?", "appeals", user.ID, Models.CaseOpen).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrAppealPending
+		}
+
+		if err := tx.Create(&appeal).Error; err != nil {
+			return err
+		}
+		open := true
+		moderationCase := Models.ModerationCase{
+			SubjectType:   "appeals",
+			SubjectID:     appeal.ID,
+			SubjectUserID: user.ID,
+			Status:        Models.CaseOpen,
+			Open:          &open,
+		}
+		if err := tx.Create(&moderationCase).Error; err != nil {
+			return err
+		}
+		appeal.CaseID = moderationCase.ID
+		return tx.Model(&appeal).Update("case_id", moderationCase.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &appeal, nil
+}
+
+// HideBanned is a query scope that leaves out the rows whose column references a banned user.
+//
+// Example usage:
+//
+//	db.Scopes(services.HideBanned("posts.user_id")).Find(&posts)
+func HideBanned(column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		query := db.Session(&gorm.Session{NewDB: true})
+		return db.Where(notIn(column), query.Model(&Models.User{}).Select("id").Scopes(bannedUsers))
+	}
+}
+
+// bannedUsers is a query scope on users that keeps the users whose ban has not expired.
+func bannedUsers(db *gorm.DB) *gorm.DB {
+	return db.Where("state = ? AND (state_expires_at IS NULL OR state_expires_at > ?)", Models.AccountBanned, time.Now())
+}
+
+// setAccountState changes the state of the user's account without notifying the user.
+func setAccountState(db *gorm.DB, user *Models.User, state Models.AccountState, reason string, expiresAt *time.Time) error {
+	if state == Models.AccountActive {
+		reason = ""
+		expiresAt = nil
+	}
+	now := time.Now()
+	if err := db.Model(user).Updates(map[string]interface{}{
+		"state":            state,
+		"state_reason":     reason,
+		"state_changed_at": now,
+		"state_expires_at": expiresAt,
+	}).Error; err != nil {
+		return err
+	}
+	user.State = state
+	user.StateReason = reason
+	user.StateChangedAt = &now
+	user.StateExpiresAt = expiresAt
+
+	if user.IsSuspended() {
+		return RevokeOtherSessions(db, user.ID, "")
+	}
+	return nil
+}
+
+// notifyAccountState emails the user about the current state of their account in the background.
+func notifyAccountState(user Models.User) {
+	go func() {
+		if err := sendAccountStateNotice(user); err != nil {
+			log.Println("failed to send account state notice:", err)
+		}
+	}()
+}
+
+func sendAccountStateNotice(user Models.User) error {
+	appConfig := config.LoadAppConfig()
+
+	var subject, summary string
+	switch user.State {
+	case Models.AccountRestricted:
+		subject = "Your account has been restricted"
+		summary = fmt.Sprintf("Your %s account has been restricted. You can still log in and read, but you can't post, comment or interact with other users.", appConfig.Name)
+	case Models.AccountSuspended:
+		subject = "Your account has been suspended"
+		summary = fmt.Sprintf("Your %s account has been suspended. You can't log in while it is suspended.", appConfig.Name)
+	case Models.AccountBanned:
+		subject = "Your account has been banned"
+		summary = fmt.Sprintf("Your %s account has been banned. You can't log in and your content is no longer shown.", appConfig.Name)
+	default:
+		subject = "Your account has been reinstated"
+		summary = fmt.Sprintf("Your %s account is active again. Welcome back!", appConfig.Name)
+	}
+
+	details := []string{}
+	if user.StateReason != "" {
+		details = append(details, "Reason: "+user.StateReason)
+	}
+	if user.StateExpiresAt != nil {
+		details = append(details, "Until: "+user.StateExpiresAt.Format("January 2, 2006 15:04 MST"))
+	}
+	if user.State != Models.AccountActive {
+		details = append(details, "If you think this is a mistake, you can appeal the decision.")
+	}
+
+	textContent := summary
+	htmlContent := "<p>" + html.EscapeString(summary) + "</p>"
+	for _, detail := range details {
+		textContent += "\n\n" + detail
+		htmlContent += "<p>" + html.EscapeString(detail) + "</p>"
+	}
+
+	noticeEmail := &mail.Mailable{
+		To: []string{user.Email},
+		Content: struct {
+			Subject string
+			Html    string
+			Text    string
+		}{
+			Subject: subject,
+			Text:    textContent,
+			Html:    htmlContent,
+		},
+	}
+
+	return noticeEmail.Send()
+}
+
+func sendAppealRejected(email, note string) error {
+	appConfig := config.LoadAppConfig()
+	textContent := fmt.Sprintf("Our moderators reviewed your appeal on %s and decided to keep the decision about your account.", appConfig.Name)
+	htmlContent := "<p>" + html.EscapeString(textContent) + "</p>"
+	if note != "" {
+		textContent += "\n\n" + note
+		htmlContent += "<p>" + html.EscapeString(note) + "</p>"
+	}
+
+	rejectedEmail := &mail.Mailable{
+		To: []string{email},
+		Content: struct {
+			Subject string
+			Html    string
+			Text    string
+		}{
+			Subject: "Your appeal has been reviewed",
+			Text:    textContent,
+			Html:    htmlContent,
+		},
+	}
+
+	return rejectedEmail.Send()
+}
@@ -17,9 +17,6 @@ func EditTags(db *gorm.DB, postID string, hashtags []Models.Tag, userID uint) er
 			return result.Error
 		}
 	}
-	if post.UserID != userID {
-		return errors.New("you are not authorized to update post")
-	}
 	existingTags := make(map[string]*Models.Tag)
 	for _, tag := range post.Hashtags {
 		existingTags[tag.Title] = tag
@@ -39,7 +36,7 @@ func EditTags(db *gorm.DB, postID string, hashtags []Models.Tag, userID uint) er
 			if result.Error != nil {
 				if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 					// Tag doesn't exist, create a new record for it
-					newTag := &Models.Tag{Title: hashtag.Title, UserID: userID}
+					newTag := &Models.Tag{Title: hashtag.Title, UserID: &userID}
 					result := db.Create(newTag)
 					if result.Error != nil {
 						return result.Error
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"gonga/app/Models"
+	"gonga/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount is the number of recovery codes generated for a user.
+const recoveryCodeCount = 8
+
+// GenerateRecoveryCodes replaces the user's recovery codes with a fresh set and returns them in plain text.
+// The plain codes are only available at this point, the database stores their bcrypt hashes.
+func GenerateRecoveryCodes(db *gorm.DB, userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&Models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		for i := 0; i < recoveryCodeCount; i++ {
+			random := make([]byte, 5)
+			if _, err := rand.Read(random); err != nil {
+				return err
+			}
+			code := hex.EncodeToString(random)
+			code = code[:5] + "-" + code[5:]
+
+			hashed, err := utils.HashPassword(code)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&Models.TwoFactorRecoveryCode{UserID: userID, Code: hashed}).Error; err != nil {
+				return err
+			}
+			codes = append(codes, code)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// UseRecoveryCode checks the code against the user's unused recovery codes and marks the matching one as used.
+func UseRecoveryCode(db *gorm.DB, userID uint, code string) bool {
+	code = strings.ToLower(strings.TrimSpace(code))
+
+	var recoveryCodes []Models.TwoFactorRecoveryCode
+	if err := db.Where("user_id = ? AND used_at IS NULL", userID).Find(&recoveryCodes).Error; err != nil {
+		return false
+	}
+
+	for _, recoveryCode := range recoveryCodes {
+		if utils.VerifyPassword(recoveryCode.Code, code) != nil {
+			continue
+		}
+		// Only the request that flips used_at wins, a concurrent replay of the same code is rejected
+		now := time.Now()
+		result := db.Model(&Models.TwoFactorRecoveryCode{}).
+			Where("id = ? AND used_at IS NULL", recoveryCode.ID).
+			Update("used_at", &now)
+		return result.Error == nil && result.RowsAffected == 1
+	}
+
+	return false
+}
+
+// VerifyTwoFactorCode checks a TOTP code for the user and records its time step so it cannot be replayed.
+func VerifyTwoFactorCode(db *gorm.DB, user *Models.User, code string) error {
+	if user.TwoFactorSecret == "" {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	counter, ok := utils.VerifyTOTPOnce(user.TwoFactorSecret, code, user.TwoFactorLastCounter, time.Now())
+	if !ok {
+		return errors.New("invalid two-factor authentication code")
+	}
+
+	result := db.Model(&Models.User{}).
+		Where("id = ? AND two_factor_last_counter < ?", user.ID, counter).
+		Update("two_factor_last_counter", counter)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("invalid two-factor authentication code")
+	}
+	user.TwoFactorLastCounter = counter
+
+	return nil
+}
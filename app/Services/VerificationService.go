@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"gonga/app/Models"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+var ErrUsernameTaken = errors.New("this username is already taken")
+
+// GrantBadge gives the user the verification badge, replacing the badge they had. actorID is the admin
+// granting it, the grant is added to the user's verification history.
+func GrantBadge(db *gorm.DB, user *Models.User, badge Models.BadgeType, actorID uint, note string) error {
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{"badge": badge, "verified_at": now}).Error; err != nil {
+			return err
+		}
+		user.Badge = badge
+		user.VerifiedAt = &now
+		return logVerification(tx, user, badge, Models.VerificationGranted, actorID, note)
+	})
+}
+
+// RevokeBadge removes the user's verification badge. Revoking the badge of a user who has none is not an error.
+func RevokeBadge(db *gorm.DB, user *Models.User, actorID uint, note string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return revokeBadge(tx, user, actorID, note)
+	})
+}
+
+// ChangeUsername renames the user. A badge vouches for the name it was granted to, so the user's badge
+// is revoked and has to be granted again for the new username. Run it in the transaction that saves the
+// rest of the profile, so that a failed save doesn't leave the user renamed.
+func ChangeUsername(db *gorm.DB, user *Models.User, username string) error {
+	if username == user.Username {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Unscoped().Model(&Models.User{}).Where("username = ? AND id <> ?", username, user.ID).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrUsernameTaken
+		}
+
+		note := fmt.Sprintf("username changed from %s to %s", user.Username, username)
+		if err := revokeBadge(tx, user, 0, note); err != nil {
+			return err
+		}
+		// The count doesn't lock anything, a concurrent rename to the same name is caught by the unique index
+		if err := tx.Model(user).Update("username", username).Error; err != nil {
+			if isDuplicateKey(err) {
+				return ErrUsernameTaken
+			}
+			return err
+		}
+		user.Username = username
+		return nil
+	})
+}
+
+func revokeBadge(db *gorm.DB, user *Models.User, actorID uint, note string) error {
+	if !user.IsVerified() {
+		return nil
+	}
+	badge := user.Badge
+	if err := db.Model(user).Updates(map[string]interface{}{"badge": "", "verified_at": nil}).Error; err != nil {
+		return err
+	}
+	user.Badge = ""
+	user.VerifiedAt = nil
+	return logVerification(db, user, badge, Models.VerificationRevoked, actorID, note)
+}
+
+func logVerification(db *gorm.DB, user *Models.User, badge Models.BadgeType, action Models.VerificationAction, actorID uint, note string) error {
+	return db.Create(&Models.VerificationEvent{
+		UserID:   user.ID,
+		Username: user.Username,
+		Badge:    badge,
+		Action:   action,
+		ActorID:  actorID,
+		Note:     note,
+	}).Error
+}
+
+// isDuplicateKey reports whether err is MySQL's duplicate entry error for a unique index.
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
@@ -1,16 +1,27 @@
 package bootstrap
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	middlewares "gonga/app/Http/Middlewares"
+	"gonga/config"
 	"gonga/database"
-	_ "gonga/docs"
+	_ "gonga/docs/v1"
 	"gonga/packages"
+	auth "gonga/packages/Auth"
+	logger "gonga/packages/Logger"
+	queue "gonga/packages/Queue"
+	server "gonga/packages/Server"
 	"gonga/routes"
-	"gonga/utils"
+	"log/slog"
 	"net/http"
+	"os/signal"
+	"syscall"
 
 	"github.com/pterm/pterm"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"github.com/swaggo/swag"
 	"gorm.io/gorm"
 )
 
@@ -22,23 +33,43 @@ type Application struct {
 
 // NewApplication creates a new instance of the Golang application.
 func NewApplication() *Application {
+	// Send the standard library's log package through the application logger as well
+	slog.SetDefault(logger.Default())
+
 	app := &Application{
-		Router: packages.NewRouter(),
+		Router: packages.Default(),
 	}
 	return app
 }
 
 // RegisterRoutes registers the application's routes.
 func (app *Application) RegisterApiRoutes() {
-	// default middlewares
+	// default middlewares, the request ID, log and recovery middlewares wrap the router in Handler
+	app.Router.Use(middlewares.RouteLogMiddleware).StrictSlash(true)
 	app.Router.Use(middlewares.CorsMiddleware).StrictSlash(true)
 	app.Router.Use(middlewares.ThrottleMiddleware).StrictSlash(true)
-	app.Router.Use(middlewares.LogMiddleware).StrictSlash(true)
 
-	// Serve swagger UI
-	app.Router.PathPrefix("/docs/").Handler(httpSwagger.Handler())
+	// Every API version has its own routes, requests without a version are served by v1
+	// so the apps that don't send one keep working
+	app.Router.Version("v1", func(v1 *packages.MyRouter) {
+		routes.RegisterApiRoutes(v1, app.DB)
+	})
+
+	// Serve swagger UI, every API version has its own docs at /docs/{version}/
+	for _, version := range app.Router.APIVersions() {
+		if swag.GetSwagger(version) != nil {
+			app.Router.PathPrefix("/docs/" + version + "/").Handler(httpSwagger.Handler(httpSwagger.InstanceName(version)))
+		}
+	}
+	app.Router.PathPrefix("/docs/").Handler(http.RedirectHandler("/docs/"+app.Router.DefaultVersion()+"/index.html", http.StatusFound))
+}
 
-	routes.RegisterApiRoutes(app.Router, app.DB)
+// Handler returns the handler of the server. The request ID, log and recovery middlewares wrap the
+// router instead of being registered on it, mux only runs its middlewares for matched routes, so
+// that they see every response: the 404 and 405 errors of the router and the errors of the other
+// middlewares.
+func (app *Application) Handler() http.Handler {
+	return middlewares.RequestIDMiddleware(middlewares.LogMiddleware(middlewares.RecoveryMiddleware(app.Router)))
 }
 
 // ConnectDatabase connects to database.
@@ -52,10 +83,74 @@ func (app *Application) ConnectDatabase() error {
 }
 
 // Run starts the Golang application.
+//
+// It refuses to start when the token signing keys can't be loaded, for example when APP_KEY is missing in production.
+// On SIGTERM or SIGINT it stops accepting connections, waits up to SERVER_SHUTDOWN_TIMEOUT for the requests
+// and background jobs in progress, then closes the database connection.
 func (app *Application) Run() error {
-	port := utils.Env("PORT", "8080")
-	address := ":" + port
-	appUrl := utils.Env("APP_URL", "http://localhost"+address)
-	pterm.Info.Println("Server started on [" + appUrl + "]")
-	return http.ListenAndServe(address, app.Router)
+	if _, err := auth.Keys(); err != nil {
+		return err
+	}
+
+	srv, err := server.New(config.LoadServerConfig(), app.Handler())
+	if err != nil {
+		return err
+	}
+	listener, err := srv.Listen()
+	if err != nil {
+		return err
+	}
+
+	// Start the background workers and the scheduled jobs
+	jobs := queue.Default()
+	app.RegisterSchedule(jobs)
+	jobs.Start()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(listener)
+	}()
+	pterm.Info.Println("Server started on [" + srv.Address() + "]")
+
+	select {
+	case err = <-served:
+		// The server failed on its own, still stop the jobs and close the database
+	case <-ctx.Done():
+		stop()
+		logger.Default().Info("shutting down, waiting for the requests in progress")
+	}
+	return app.shutdown(srv, err)
+}
+
+// shutdown drains the server and the queue within the shutdown timeout and closes the database. It
+// returns the error the server failed with, or the first error of the shutdown.
+func (app *Application) shutdown(srv *server.Server, err error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), config.LoadServerConfig().ShutdownTimeout)
+	defer cancel()
+
+	errs := []error{err}
+	if err := srv.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("shutting down the server: %w", err))
+	}
+	if err := queue.Default().Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("shutting down the queue: %w", err))
+	}
+	if app.DB != nil {
+		if db, err := app.DB.DB(); err == nil {
+			if err := db.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("closing the database: %w", err))
+			}
+		}
+	}
+	logger.Close()
+
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,40 @@
+package bootstrap
+
+import (
+	"context"
+	services "gonga/app/Services"
+	cloudinary "gonga/packages/Cloudinary"
+	logger "gonga/packages/Logger"
+	queue "gonga/packages/Queue"
+	"time"
+)
+
+// RegisterSchedule registers the jobs that run periodically while the server is running.
+func (app *Application) RegisterSchedule(q *queue.Queue) {
+	// Purge accounts whose deletion grace period is over
+	q.Every("account:purge", time.Hour, func(ctx context.Context) error {
+		purged, err := services.PurgeDueAccounts(ctx, app.DB, cloudinary.NewCloudinaryClient())
+		if purged > 0 {
+			logger.Default().Info("purged deleted accounts", "count", purged)
+		}
+		return err
+	})
+
+	// Reactivate accounts whose restriction, suspension or ban expired
+	q.Every("account:lift", time.Hour, func(ctx context.Context) error {
+		lifted, err := services.LiftExpiredAccountStates(app.DB)
+		if lifted > 0 {
+			logger.Default().Info("reactivated accounts", "count", lifted)
+		}
+		return err
+	})
+
+	// Delete expired data exports
+	q.Every("export:prune", time.Hour, func(ctx context.Context) error {
+		return services.PruneDataExports(app.DB)
+	})
+
+	q.Every("idempotency:prune", time.Hour, func(ctx context.Context) error {
+		return services.PruneIdempotencyKeys(app.DB)
+	})
+}
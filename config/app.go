@@ -12,6 +12,8 @@ type AppConfig struct {
 	Env      string
 	URL      string
 	AssetURL string
+	// FrontendURL is where the pages linked from emails live, like the password reset form
+	FrontendURL string
 	Timezone string
 	Debug    bool
 }
@@ -78,6 +80,19 @@ func LoadAppConfig() *AppConfig {
 
 		AssetURL: utils.Env("ASSET_URL", "http://localhost"),
 
+		/*
+		   |--------------------------------------------------------------------------
+		   | Frontend URL
+		   |--------------------------------------------------------------------------
+		   |
+		   | Links in emails, like the password reset and email confirmation links,
+		   | open pages of the frontend, which then call the API. Set this to the
+		   | root of the frontend when it isn't served from the APP_URL.
+		   |
+		*/
+
+		FrontendURL: utils.Env("FRONTEND_URL", utils.Env("APP_URL", "http://localhost")),
+
 		/*
 		   |--------------------------------------------------------------------------
 		   | Application Timezone
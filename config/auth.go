@@ -1,7 +1,18 @@
 package config
 
+import (
+	"gonga/utils"
+	"strings"
+	"time"
+)
+
 type AuthConfig struct {
-	Guards map[string]GuardConfig
+	Guards    map[string]GuardConfig
+	Providers map[string]OAuthProviderConfig
+	Lockout   LockoutConfig
+
+	// DeletionGracePeriod is how long a deleted account can still be restored by logging in.
+	DeletionGracePeriod time.Duration
 }
 
 type GuardConfig struct {
@@ -9,6 +20,38 @@ type GuardConfig struct {
 	Provider string
 }
 
+// OAuthProviderConfig configures an OAuth2 or OpenID Connect identity provider used for social login.
+//
+// When Issuer is set the provider is treated as OpenID Connect and its endpoints are discovered from
+// "{Issuer}/.well-known/openid-configuration". Otherwise AuthURL, TokenURL and UserInfoURL must be set.
+type OAuthProviderConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	Issuer       string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// LockoutConfig controls how failed logins are throttled.
+//
+// After FreeAttempts failures every further attempt has to wait BaseDelay, doubled for each extra
+// failure and capped at MaxDelay. After LockoutAttempts failures the account is locked for
+// LockoutDuration. Failures older than Window, or before the last successful login, are not counted.
+type LockoutConfig struct {
+	FreeAttempts      int
+	LockoutAttempts   int
+	IPFreeAttempts    int
+	IPLockoutAttempts int
+	BaseDelay         time.Duration
+	MaxDelay          time.Duration
+	LockoutDuration   time.Duration
+	Window            time.Duration
+}
+
 func LoadAuthConfig() *AuthConfig {
 	return &AuthConfig{
 		Guards: map[string]GuardConfig{
@@ -21,6 +64,80 @@ func LoadAuthConfig() *AuthConfig {
 				Provider: "users",
 			},
 		},
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Social Login Providers
+		   |--------------------------------------------------------------------------
+		   |
+		   | OAUTH_PROVIDERS is a comma separated list of provider names, for example
+		   | "google,github". Each provider is configured with OAUTH_{NAME}_* env
+		   | variables such as OAUTH_GOOGLE_CLIENT_ID and OAUTH_GOOGLE_ISSUER.
+		   |
+		*/
+
+		Providers: loadOAuthProviders(),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Login Throttling
+		   |--------------------------------------------------------------------------
+		   |
+		   | Failed logins are tracked per username and per IP address. The IP
+		   | limits are higher because many users can share one address.
+		   |
+		*/
+
+		Lockout: LockoutConfig{
+			FreeAttempts:      utils.EnvInt("LOGIN_FREE_ATTEMPTS", 3),
+			LockoutAttempts:   utils.EnvInt("LOGIN_LOCKOUT_ATTEMPTS", 10),
+			IPFreeAttempts:    utils.EnvInt("LOGIN_IP_FREE_ATTEMPTS", 20),
+			IPLockoutAttempts: utils.EnvInt("LOGIN_IP_LOCKOUT_ATTEMPTS", 100),
+			BaseDelay:         time.Duration(utils.EnvInt("LOGIN_BASE_DELAY_SECONDS", 1)) * time.Second,
+			MaxDelay:          time.Duration(utils.EnvInt("LOGIN_MAX_DELAY_SECONDS", 300)) * time.Second,
+			LockoutDuration:   time.Duration(utils.EnvInt("LOGIN_LOCKOUT_MINUTES", 30)) * time.Minute,
+			Window:            time.Duration(utils.EnvInt("LOGIN_ATTEMPT_WINDOW_MINUTES", 60)) * time.Minute,
+		},
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Account Deletion
+		   |--------------------------------------------------------------------------
+		   |
+		   | Deleted accounts are kept for this many days. Logging in during that
+		   | time cancels the deletion. Afterwards the account and its content are
+		   | purged by the scheduled "account:purge" job.
+		   |
+		*/
+
+		DeletionGracePeriod: time.Duration(utils.EnvInt("ACCOUNT_DELETION_GRACE_DAYS", 30)) * 24 * time.Hour,
+	}
+
+}
+
+func loadOAuthProviders() map[string]OAuthProviderConfig {
+	providers := map[string]OAuthProviderConfig{}
+	appURL := strings.TrimRight(utils.Env("APP_URL", "http://localhost"), "/")
+
+	for _, name := range strings.Split(utils.Env("OAUTH_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+
+		providers[name] = OAuthProviderConfig{
+			Name:         name,
+			ClientID:     utils.Env(prefix+"CLIENT_ID", ""),
+			ClientSecret: utils.Env(prefix+"CLIENT_SECRET", ""),
+			Issuer:       utils.Env(prefix+"ISSUER", ""),
+			AuthURL:      utils.Env(prefix+"AUTH_URL", ""),
+			TokenURL:     utils.Env(prefix+"TOKEN_URL", ""),
+			UserInfoURL:  utils.Env(prefix+"USERINFO_URL", ""),
+			RedirectURL:  utils.Env(prefix+"REDIRECT_URL", appURL+"/auth/"+name+"/callback"),
+			Scopes:       strings.Fields(utils.Env(prefix+"SCOPES", "openid email profile")),
+		}
 	}
 
+	return providers
 }
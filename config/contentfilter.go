@@ -0,0 +1,67 @@
+package config
+
+import (
+	"gonga/utils"
+	"time"
+)
+
+// ContentFilterConfig configures the rules that posts and comments pass before they are published.
+// Actions are "flag", "hold" or "reject".
+type ContentFilterConfig struct {
+	BannedWords       []string
+	BannedWordsFile   string
+	BannedWordsAction string
+
+	MaxLinks    int
+	LinksAction string
+
+	DuplicateLimit  int
+	DuplicateWindow time.Duration
+	DuplicateAction string
+}
+
+func LoadContentFilterConfig() *ContentFilterConfig {
+	return &ContentFilterConfig{
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Banned Words
+		   |--------------------------------------------------------------------------
+		   |
+		   | Comma separated words and phrases, and a file with one per line. They
+		   | also match when disguised with look-alike characters, punctuation or
+		   | spaces between the letters.
+		   |
+		*/
+
+		BannedWords:       splitList(utils.Env("CONTENT_BANNED_WORDS", "")),
+		BannedWordsFile:   utils.Env("CONTENT_BANNED_WORDS_FILE", "storage/app/banned-words.txt"),
+		BannedWordsAction: utils.Env("CONTENT_BANNED_WORDS_ACTION", "reject"),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Links
+		   |--------------------------------------------------------------------------
+		   |
+		   | Content with more links than this is a common sign of spam.
+		   |
+		*/
+
+		MaxLinks:    utils.EnvInt("CONTENT_MAX_LINKS", 3),
+		LinksAction: utils.Env("CONTENT_LINKS_ACTION", "hold"),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Repeated Content
+		   |--------------------------------------------------------------------------
+		   |
+		   | How often a user may post the same text within the window. 0 allows
+		   | any number of repeats.
+		   |
+		*/
+
+		DuplicateLimit:  utils.EnvInt("CONTENT_DUPLICATE_LIMIT", 3),
+		DuplicateWindow: time.Duration(utils.EnvInt("CONTENT_DUPLICATE_WINDOW_MINUTES", 60)) * time.Minute,
+		DuplicateAction: utils.Env("CONTENT_DUPLICATE_ACTION", "reject"),
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"gonga/utils"
+	"time"
+)
+
+// ExportConfig configures the personal data exports users can request.
+type ExportConfig struct {
+	Path         string
+	LinkLifetime time.Duration
+}
+
+func LoadExportConfig() *ExportConfig {
+	return &ExportConfig{
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Personal Data Exports
+		   |--------------------------------------------------------------------------
+		   |
+		   | Exports are written to this directory and can be downloaded with the
+		   | emailed link until it expires. Expired exports are deleted by the
+		   | scheduler.
+		   |
+		*/
+
+		Path:         utils.Env("EXPORT_PATH", "storage/app/exports"),
+		LinkLifetime: time.Duration(utils.EnvInt("EXPORT_LINK_LIFETIME_HOURS", 48)) * time.Hour,
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"gonga/utils"
+	"strings"
+	"time"
+)
+
+// IdempotencyConfig configures the replay of requests sent with an Idempotency-Key header.
+type IdempotencyConfig struct {
+	Store string
+	TTL   time.Duration
+	// Lease is how long a request holds its key before it completes
+	Lease time.Duration
+}
+
+func LoadIdempotencyConfig() *IdempotencyConfig {
+	return &IdempotencyConfig{
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Idempotency Store
+		   |--------------------------------------------------------------------------
+		   |
+		   | "memory" keeps the keys inside the server process, "database" keeps
+		   | them in the idempotency_keys table. Use the database when several
+		   | servers share the traffic, a retry may reach another server.
+		   |
+		*/
+
+		Store: strings.ToLower(utils.Env("IDEMPOTENCY_STORE", "memory")),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Key Lifetime
+		   |--------------------------------------------------------------------------
+		   |
+		   | Retries with the same key get the stored response for this many hours
+		   | after the first request, later the key can be used again.
+		   |
+		*/
+
+		TTL: time.Duration(utils.EnvInt("IDEMPOTENCY_TTL_HOURS", 24)) * time.Hour,
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Key Lease
+		   |--------------------------------------------------------------------------
+		   |
+		   | Retries are rejected while the first request is processed, for up to
+		   | this many seconds. When its server crashed meanwhile, retries can
+		   | claim the key again after that.
+		   |
+		*/
+
+		Lease: time.Duration(utils.EnvInt("IDEMPOTENCY_LEASE_SECONDS", 60)) * time.Second,
+	}
+}
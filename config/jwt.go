@@ -0,0 +1,65 @@
+package config
+
+import (
+	"gonga/utils"
+	"strings"
+)
+
+// JWTConfig describes the keys used to sign and verify access tokens.
+//
+// HS256 signs with Secret. RS256 and EdDSA sign with the PEM encoded private key at PrivateKeyPath.
+// The previous keys are only used to verify tokens that were issued before a key rotation.
+type JWTConfig struct {
+	Algorithm        string
+	Secret           string
+	PrivateKeyPath   string
+	PreviousSecrets  []string
+	PreviousKeyPaths []string
+	Production       bool
+}
+
+func LoadJWTConfig() *JWTConfig {
+	return &JWTConfig{
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Signing Algorithm
+		   |--------------------------------------------------------------------------
+		   |
+		   | HS256 signs tokens with APP_KEY. RS256 and EdDSA sign tokens with the
+		   | private key in JWT_PRIVATE_KEY, their public keys are published on
+		   | /.well-known/jwks.json so other services can verify our tokens.
+		   |
+		*/
+
+		Algorithm:      utils.Env("JWT_ALGORITHM", "HS256"),
+		Secret:         utils.Env("APP_KEY", ""),
+		PrivateKeyPath: utils.Env("JWT_PRIVATE_KEY", ""),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Previous Keys
+		   |--------------------------------------------------------------------------
+		   |
+		   | Comma separated keys that were rotated out. Tokens signed with them
+		   | stay valid until they expire, new tokens are never signed with them.
+		   | "key:generate --rotate" moves the current key here.
+		   |
+		*/
+
+		PreviousSecrets:  splitList(utils.Env("APP_PREVIOUS_KEYS", "")),
+		PreviousKeyPaths: splitList(utils.Env("JWT_PREVIOUS_KEYS", "")),
+
+		Production: strings.EqualFold(utils.Env("APP_ENV", "production"), "production"),
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -1 +1,108 @@
-package config
\ No newline at end of file
+package config
+
+import (
+	"gonga/utils"
+	"time"
+)
+
+// LoggerConfig configures where the application logs go.
+type LoggerConfig struct {
+	// Default is the name of the channel the application logs to
+	Default string
+	// Level is the lowest level that is logged: debug, info, warn or error
+	Level    string
+	Channels map[string]LogChannelConfig
+	// QueryLevel is the level of the database queries that are logged: silent, error, warn or info
+	QueryLevel string
+	// SlowQueryThreshold is the duration after which a query is logged as slow at the warn level
+	SlowQueryThreshold time.Duration
+}
+
+// LogChannelConfig configures a log channel.
+type LogChannelConfig struct {
+	// Driver is stack, single, daily or stderr
+	Driver string
+	// Channels are the channels a stack channel writes to
+	Channels []string
+	// Level overrides the level of the logger for this channel
+	Level string
+	// Format is json or text
+	Format string
+	// Path is the file of the single and daily channels, the daily channel adds the date to its name
+	Path string
+	// MaxSize is the size in megabytes after which the single channel starts a new file, 0 never does
+	MaxSize int
+	// MaxFiles is the number of old files that are kept, 0 keeps all of them
+	MaxFiles int
+	// MaxAge is how long old files are kept, 0 keeps them until there are more than MaxFiles
+	MaxAge time.Duration
+}
+
+func LoadLoggerConfig() *LoggerConfig {
+	return &LoggerConfig{
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Default Log Channel
+		   |--------------------------------------------------------------------------
+		   |
+		   | The channel the application writes its logs to, one of the channels
+		   | below. The stack channel writes to several channels at once, which
+		   | are listed in LOG_STACK.
+		   |
+		*/
+
+		Default: utils.Env("LOG_CHANNEL", "stack"),
+
+		Level: utils.Env("LOG_LEVEL", "info"),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Log Channels
+		   |--------------------------------------------------------------------------
+		   |
+		   | The files of the single channel rotate when they reach their maximum
+		   | size, the daily channel starts a new file every day. Both delete
+		   | their old files by count and age. Files are written as JSON.
+		   |
+		*/
+
+		Channels: map[string]LogChannelConfig{
+			"stack": {
+				Driver:   "stack",
+				Channels: splitList(utils.Env("LOG_STACK", "single,stderr")),
+			},
+			"single": {
+				Driver:   "single",
+				Format:   "json",
+				Path:     utils.Env("LOG_PATH", "storage/logs/app.log"),
+				MaxSize:  utils.EnvInt("LOG_MAX_SIZE", 100),
+				MaxFiles: utils.EnvInt("LOG_MAX_FILES", 5),
+			},
+			"daily": {
+				Driver: "daily",
+				Format: "json",
+				Path:   utils.Env("LOG_PATH", "storage/logs/app.log"),
+				MaxAge: time.Duration(utils.EnvInt("LOG_DAILY_DAYS", 14)) * 24 * time.Hour,
+			},
+			"stderr": {
+				Driver: "stderr",
+				Format: utils.Env("LOG_STDERR_FORMAT", "text"),
+			},
+		},
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Database Queries
+		   |--------------------------------------------------------------------------
+		   |
+		   | Failed queries are logged at the error level and slow queries at the
+		   | warn level. Set DB_LOG_LEVEL to info and LOG_LEVEL to debug to log
+		   | every query while developing.
+		   |
+		*/
+
+		QueryLevel:         utils.Env("DB_LOG_LEVEL", "warn"),
+		SlowQueryThreshold: time.Duration(utils.EnvInt("DB_SLOW_QUERY_MS", 200)) * time.Millisecond,
+	}
+}
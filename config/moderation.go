@@ -0,0 +1,30 @@
+package config
+
+import (
+	"gonga/utils"
+)
+
+// ModerationConfig configures the handling of reported content.
+type ModerationConfig struct {
+	// AutoHideThreshold is the number of reports from different users after which a post or comment is
+	// hidden until a moderator reviews it. 0 disables hiding.
+	AutoHideThreshold int
+}
+
+func LoadModerationConfig() *ModerationConfig {
+	return &ModerationConfig{
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Automatic Hiding
+		   |--------------------------------------------------------------------------
+		   |
+		   | Reported posts and comments stay visible until a moderator removes
+		   | them, unless this many different users reported them. Then they
+		   | are hidden until the report is resolved.
+		   |
+		*/
+
+		AutoHideThreshold: utils.EnvInt("MODERATION_AUTO_HIDE_REPORTS", 5),
+	}
+}
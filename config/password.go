@@ -0,0 +1,61 @@
+package config
+
+import (
+	"gonga/utils"
+	"strconv"
+	"strings"
+)
+
+// PasswordConfig describes the password policy applied on registration, password reset and password change.
+type PasswordConfig struct {
+	MinLength        int
+	RequireLetters   bool
+	RequireMixedCase bool
+	RequireNumbers   bool
+	RequireSymbols   bool
+	CheckSimilarity  bool
+	BreachedListPath string
+}
+
+func LoadPasswordConfig() *PasswordConfig {
+	return &PasswordConfig{
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Password Strength
+		   |--------------------------------------------------------------------------
+		   |
+		   | The minimum length and the character classes every new password has
+		   | to contain. Passwords that contain the username or the name part of
+		   | the email address are rejected when the similarity check is enabled.
+		   |
+		*/
+
+		MinLength:        utils.EnvInt("PASSWORD_MIN_LENGTH", 8),
+		RequireLetters:   envBool("PASSWORD_REQUIRE_LETTERS", true),
+		RequireMixedCase: envBool("PASSWORD_REQUIRE_MIXED_CASE", true),
+		RequireNumbers:   envBool("PASSWORD_REQUIRE_NUMBERS", true),
+		RequireSymbols:   envBool("PASSWORD_REQUIRE_SYMBOLS", false),
+		CheckSimilarity:  envBool("PASSWORD_CHECK_SIMILARITY", true),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Breached Passwords
+		   |--------------------------------------------------------------------------
+		   |
+		   | Path to a bloom filter of known breached passwords, built with the
+		   | "password:breached" command. Leave empty to disable the check.
+		   |
+		*/
+
+		BreachedListPath: utils.Env("PASSWORD_BREACHED_FILTER", "storage/app/breached-passwords.bloom"),
+	}
+}
+
+func envBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(strings.ToLower(utils.Env(key, strconv.FormatBool(fallback))))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"gonga/utils"
+	"strings"
+)
+
+// QueueConfig configures the background job queue.
+type QueueConfig struct {
+	Connection string
+	Workers    int
+}
+
+func LoadQueueConfig() *QueueConfig {
+	return &QueueConfig{
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Queue Connection
+		   |--------------------------------------------------------------------------
+		   |
+		   | "memory" runs jobs on background workers inside the server process.
+		   | "sync" runs every job immediately in the request that dispatches it.
+		   |
+		*/
+
+		Connection: strings.ToLower(utils.Env("QUEUE_CONNECTION", "memory")),
+		Workers:    utils.EnvInt("QUEUE_WORKERS", 4),
+	}
+}
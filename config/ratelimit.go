@@ -0,0 +1,43 @@
+package config
+
+import (
+	"gonga/utils"
+)
+
+// RateLimitConfig configures the request rate limits.
+type RateLimitConfig struct {
+	// Default is the limit of every client across all routes as "requests,minutes", empty disables it
+	Default string
+	// StoreSize is the number of clients the in-memory store keeps track of
+	StoreSize int
+}
+
+func LoadRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Default Rate Limit
+		   |--------------------------------------------------------------------------
+		   |
+		   | Every client, identified by its access token or else its IP address,
+		   | may send this many requests per number of minutes to the API. Routes
+		   | can set stricter limits of their own with middlewares.Throttle.
+		   |
+		*/
+
+		Default: utils.Env("RATE_LIMIT_DEFAULT", "120,1"),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Store Size
+		   |--------------------------------------------------------------------------
+		   |
+		   | The limits are kept in memory. When more clients than this are active,
+		   | the limits of the clients that were idle the longest are forgotten.
+		   |
+		*/
+
+		StoreSize: utils.EnvInt("RATE_LIMIT_STORE_SIZE", 10000),
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"gonga/utils"
+	"time"
+)
+
+// ServerConfig configures the HTTP server.
+type ServerConfig struct {
+	// Port is the TCP port the server listens on when Socket is empty
+	Port string
+	// Socket is the path of a Unix socket to listen on instead of the port
+	Socket string
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout is how long the server waits for the requests and jobs in progress when it stops
+	ShutdownTimeout time.Duration
+
+	// TLSCertFile and TLSKeyFile enable HTTPS when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+func LoadServerConfig() *ServerConfig {
+	return &ServerConfig{
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Listen Address
+		   |--------------------------------------------------------------------------
+		   |
+		   | The server listens on PORT on all interfaces. Set SERVER_SOCKET to
+		   | listen on a Unix socket instead, for example behind a reverse proxy
+		   | on the same machine.
+		   |
+		*/
+
+		Port:   utils.Env("PORT", "8080"),
+		Socket: utils.Env("SERVER_SOCKET", ""),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Timeouts
+		   |--------------------------------------------------------------------------
+		   |
+		   | In seconds. Clients that send their request or read the response too
+		   | slowly are disconnected, and idle keep-alive connections are closed.
+		   | The write timeout also limits how long a handler can take.
+		   |
+		*/
+
+		ReadTimeout:       seconds("SERVER_READ_TIMEOUT", 15),
+		ReadHeaderTimeout: seconds("SERVER_READ_HEADER_TIMEOUT", 5),
+		WriteTimeout:      seconds("SERVER_WRITE_TIMEOUT", 60),
+		IdleTimeout:       seconds("SERVER_IDLE_TIMEOUT", 120),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | Shutdown Timeout
+		   |--------------------------------------------------------------------------
+		   |
+		   | On SIGTERM or SIGINT the server stops accepting connections and waits
+		   | this many seconds for the requests and background jobs in progress,
+		   | then closes whatever is left.
+		   |
+		*/
+
+		ShutdownTimeout: seconds("SERVER_SHUTDOWN_TIMEOUT", 30),
+
+		/*
+		   |--------------------------------------------------------------------------
+		   | TLS
+		   |--------------------------------------------------------------------------
+		   |
+		   | The PEM certificate and key to serve HTTPS with. The files are read
+		   | again when they change, renewed certificates are picked up without
+		   | restarting the server.
+		   |
+		*/
+
+		TLSCertFile: utils.Env("TLS_CERT_FILE", ""),
+		TLSKeyFile:  utils.Env("TLS_KEY_FILE", ""),
+	}
+}
+
+// seconds reads a duration in seconds from the environment.
+func seconds(key string, fallback int) time.Duration {
+	return time.Duration(utils.EnvInt(key, fallback)) * time.Second
+}
@@ -11,8 +11,8 @@ func CommentFactory() Models.Comment {
 
 	comment := Models.Comment{
 		Body:     faker.Paragraph(2, 20, 20, "."),
-		UserID:   0, // Set the appropriate user ID here
-		PostID:   0, // Set the appropriate post ID here
+		UserID:   nil, // Set the appropriate user here
+		PostID:   0,   // Set the appropriate post ID here
 		ParentID: nil,
 	}
 
@@ -15,7 +15,7 @@ func TagFactory() Models.Tag {
 		Description:  faker.Paragraph(1, 5, 15, "."),
 		Color:        faker.HexColor(),
 		Slug:         faker.Sentence(10),
-		UserID:       0, // Set the appropriate user ID here
+		UserID:       nil, // Set the appropriate user here
 	}
 
 	return tag
@@ -3,27 +3,30 @@ package database
 import (
 	"fmt"
 	"gonga/config"
-	"gonga/utils"
+	logger "gonga/packages/Logger"
 	"os"
 
 	"github.com/pterm/pterm"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
+// DB is the connection opened by Connect. It is used where a handler can't be given the
+// connection directly, such as middlewares.
+var DB *gorm.DB
+
 func Connect() (*gorm.DB, error) {
 	dbConfig := config.LoadDatabaseConfig()
 
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", dbConfig.User, dbConfig.Password, dbConfig.Host, dbConfig.Port, dbConfig.Database)
 
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+		Logger: logger.NewGormLogger(logger.Default(), config.LoadLoggerConfig()),
 	})
 
 	if err != nil {
 		pterm.Error.WithShowLineNumber(true).Println("Cannot connect to database.", err)
-		utils.Marlboro.Println("Cannot connect to database.", err)
+		logger.Default().Error("cannot connect to the database", "error", err)
 		/**
 		 * Use panic when something horribly goes wrong, i.e. an error that should have been
 		 * caught before going to production. This is why is prints the stack.
@@ -34,5 +37,6 @@ func Connect() (*gorm.DB, error) {
 		os.Exit(0)
 	}
 
+	DB = db
 	return db, nil
 }
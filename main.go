@@ -12,7 +12,7 @@ var App *bootstrap.Application
 //	@version		1.0
 //	@description	This is the Swagger documentation for the Gonga API.
 //	@host			gonga.up.railway.app
-//	@BasePath		/
+//	@BasePath		/v1
 //	@contact.name	Krishan Kumar
 //	@contact.email	your.email@example.com
 //	@contact.url	https://www.linkedin.com/in/kkumar-gcc
@@ -0,0 +1,325 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gonga/config"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// ErrNoSigningKey is returned when no key is configured for the signing algorithm.
+var ErrNoSigningKey = errors.New("auth: no signing key configured, run \"key:generate\"")
+
+// Key is a signing or verification key of a Keyring.
+type Key struct {
+	// ID is sent as the "kid" header of every token signed with the key.
+	ID string
+	// Algorithm is the only algorithm tokens verified with the key may use.
+	Algorithm string
+
+	signing   interface{}
+	verifying interface{}
+}
+
+// NewHMACKey returns an HS256 key for the secret.
+func NewHMACKey(secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, ErrNoSigningKey
+	}
+	return &Key{ID: keyID(secret), Algorithm: HS256, signing: secret, verifying: secret}, nil
+}
+
+// ParsePEMKey parses a PEM encoded RS256 or EdDSA key. Private keys can sign and verify,
+// public keys can only verify.
+func ParsePEMKey(algorithm string, data []byte) (*Key, error) {
+	key := &Key{Algorithm: algorithm}
+
+	switch algorithm {
+	case RS256:
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			key.signing, key.verifying = private, &private.PublicKey
+		} else if public, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+			key.verifying = public
+		} else {
+			return nil, fmt.Errorf("auth: invalid RS256 key: %w", err)
+		}
+	case EdDSA:
+		if private, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			key.signing, key.verifying = private, private.(ed25519.PrivateKey).Public()
+		} else if public, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+			key.verifying = public
+		} else {
+			return nil, fmt.Errorf("auth: invalid EdDSA key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("auth: unsupported algorithm %q", algorithm)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.verifying)
+	if err != nil {
+		return nil, err
+	}
+	key.ID = keyID(der)
+
+	return key, nil
+}
+
+// LoadPEMKey reads a key file written by "key:generate". The algorithm is detected from the key type.
+func LoadPEMKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := ParsePEMKey(RS256, data); err == nil {
+		return key, nil
+	}
+	if key, err := ParsePEMKey(EdDSA, data); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("auth: %s is not an RSA or Ed25519 key", path)
+}
+
+// CanSign reports whether the key has its private part.
+func (k *Key) CanSign() bool {
+	return k.signing != nil
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// keyID derives a stable key ID from the key material, so the same key always gets the same "kid".
+func keyID(material []byte) string {
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Keyring signs tokens with its current key and verifies tokens with any of its keys.
+//
+// Every token carries the ID of its key in the "kid" header and may only use the algorithm of that key,
+// so a token can't switch to another algorithm or to an HMAC secret derived from a public key.
+type Keyring struct {
+	current *Key
+	keys    map[string]*Key
+	order   []*Key
+}
+
+// NewKeyring returns a keyring that signs with current and also accepts tokens signed with previous.
+func NewKeyring(current *Key, previous ...*Key) (*Keyring, error) {
+	if current == nil || !current.CanSign() {
+		return nil, ErrNoSigningKey
+	}
+
+	kr := &Keyring{current: current, keys: map[string]*Key{}}
+	for _, key := range append([]*Key{current}, previous...) {
+		if _, exists := kr.keys[key.ID]; exists {
+			continue
+		}
+		kr.keys[key.ID] = key
+		kr.order = append(kr.order, key)
+	}
+	return kr, nil
+}
+
+// Current returns the signing key.
+func (kr *Keyring) Current() *Key {
+	return kr.current
+}
+
+// Sign returns the signed token for the claims.
+func (kr *Keyring) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(kr.current.method(), claims)
+	token.Header["kid"] = kr.current.ID
+	return token.SignedString(kr.current.signing)
+}
+
+// Parse verifies the token and returns its claims.
+//
+// Tokens without a "kid" header were issued before keys had IDs, they are only accepted when they are
+// signed with one of the HS256 keys.
+func (kr *Keyring) Parse(tokenString string) (jwt.MapClaims, error) {
+	header, err := tokenHeader(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*Key
+	if kid, ok := header["kid"].(string); ok {
+		key, exists := kr.keys[kid]
+		if !exists {
+			return nil, errors.New("unknown signing key")
+		}
+		candidates = []*Key{key}
+	} else {
+		for _, key := range kr.order {
+			if key.Algorithm == HS256 {
+				candidates = append(candidates, key)
+			}
+		}
+	}
+
+	var lastErr error = errors.New("invalid token")
+	for _, key := range candidates {
+		key := key
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			return key.verifying, nil
+		}, jwt.WithValidMethods([]string{key.Algorithm}))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !token.Valid {
+			continue
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			return nil, errors.New("invalid token claims")
+		}
+		return claims, nil
+	}
+	return nil, lastErr
+}
+
+// tokenHeader decodes the header of a token without verifying it.
+func tokenHeader(tokenString string) (map[string]interface{}, error) {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+	return token.Header, nil
+}
+
+// JSONWebKey is a public key in the JWK format (RFC 7517).
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is the document served on /.well-known/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public keys of the keyring. HS256 secrets are never published.
+func (kr *Keyring) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range kr.order {
+		switch public := key.verifying.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JSONWebKey{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JSONWebKey{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	return set
+}
+
+// LoadKeyring builds the keyring described by the configuration.
+//
+// Outside of production a missing HS256 secret is replaced by a random one, tokens then stop working
+// when the application restarts. In production a missing key is an error.
+func LoadKeyring(cfg *config.JWTConfig) (*Keyring, error) {
+	var current *Key
+	var err error
+
+	switch cfg.Algorithm {
+	case HS256:
+		current, err = NewHMACKey([]byte(cfg.Secret))
+		if errors.Is(err, ErrNoSigningKey) && !cfg.Production {
+			log.Println("APP_KEY is not set, using a temporary key. Run \"key:generate\" to create one.")
+			secret := make([]byte, 64)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+			current, err = NewHMACKey(secret)
+		}
+	case RS256, EdDSA:
+		if cfg.PrivateKeyPath == "" {
+			return nil, ErrNoSigningKey
+		}
+		var data []byte
+		data, err = os.ReadFile(cfg.PrivateKeyPath)
+		if err == nil {
+			current, err = ParsePEMKey(cfg.Algorithm, data)
+		}
+		if err == nil && !current.CanSign() {
+			err = fmt.Errorf("auth: %s is not a private key", cfg.PrivateKeyPath)
+		}
+	default:
+		err = fmt.Errorf("auth: unsupported JWT_ALGORITHM %q", cfg.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var previous []*Key
+	for _, secret := range cfg.PreviousSecrets {
+		key, err := NewHMACKey([]byte(secret))
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+	for _, path := range cfg.PreviousKeyPaths {
+		key, err := LoadPEMKey(path)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+
+	return NewKeyring(current, previous...)
+}
+
+var (
+	defaultKeyring     *Keyring
+	defaultKeyringErr  error
+	defaultKeyringOnce sync.Once
+)
+
+// Keys returns the application keyring, loaded from the environment on first use.
+func Keys() (*Keyring, error) {
+	defaultKeyringOnce.Do(func() {
+		defaultKeyring, defaultKeyringErr = LoadKeyring(config.LoadJWTConfig())
+	})
+	return defaultKeyring, defaultKeyringErr
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"gonga/app/Models"
+	"net/http"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnauthenticated is returned when a request did not pass the AuthMiddleware.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// principalKey is the context key of the *Principal. It is unexported so that only this package
+// can set the principal.
+type principalKey struct{}
+
+// Principal is the authenticated caller of a request, set by the AuthMiddleware.
+type Principal struct {
+	// UserID is the ID of the authenticated user.
+	UserID uint
+	// TokenID is the ID ("jti") of the access token, which is also the session ID.
+	TokenID string
+	// Scopes are the abilities granted to the token. "*" grants every scope.
+	Scopes []string
+	// Roles are the roles of the user when the request was authenticated.
+	Roles []Models.Role
+
+	db       *gorm.DB
+	userOnce sync.Once
+	user     *Models.User
+	userErr  error
+}
+
+// NewPrincipal returns a principal whose user is loaded from db on first use.
+func NewPrincipal(db *gorm.DB, userID uint, tokenID string, scopes []string, roles []Models.Role) *Principal {
+	return &Principal{
+		UserID:  userID,
+		TokenID: tokenID,
+		Scopes:  scopes,
+		Roles:   roles,
+		db:      db,
+	}
+}
+
+// WithPrincipal returns a copy of ctx that carries the principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal of ctx.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// Current returns the principal of the request or ErrUnauthenticated.
+//
+// Example usage:
+//
+//	principal, err := auth.Current(r)
+//	if err != nil {
+//		utils.HandleError(w, err, http.StatusUnauthorized)
+//		return
+//	}
+func Current(r *http.Request) (*Principal, error) {
+	principal, ok := FromContext(r.Context())
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return principal, nil
+}
+
+// ID returns the ID of the authenticated user of the request.
+//
+// Example usage:
+//
+//	userID, err := auth.ID(r)
+//	if err != nil {
+//		utils.HandleError(w, err, http.StatusUnauthorized)
+//		return
+//	}
+func ID(r *http.Request) (uint, error) {
+	principal, err := Current(r)
+	if err != nil {
+		return 0, err
+	}
+	return principal.UserID, nil
+}
+
+// User returns the authenticated user of the request. The user is loaded from the database on the
+// first call and shared by every later call during the same request, including calls from middlewares.
+//
+// Example usage:
+//
+//	user, err := auth.User(r)
+//	if err != nil {
+//		utils.HandleError(w, err, http.StatusUnauthorized)
+//		return
+//	}
+func User(r *http.Request) (*Models.User, error) {
+	principal, err := Current(r)
+	if err != nil {
+		return nil, err
+	}
+	return principal.User()
+}
+
+// User returns the principal's user, loading it on the first call.
+func (p *Principal) User() (*Models.User, error) {
+	p.userOnce.Do(func() {
+		if p.db == nil {
+			p.userErr = errors.New("auth: principal has no database connection")
+			return
+		}
+		var user Models.User
+		if err := p.db.First(&user, p.UserID).Error; err != nil {
+			p.userErr = errors.New("user not found")
+			return
+		}
+		p.user = &user
+	})
+	return p.user, p.userErr
+}
+
+// HasScope reports whether the token was granted the scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if granted == "*" || granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the user had one of the roles when the request was authenticated.
+func (p *Principal) HasRole(roles ...Models.Role) bool {
+	for _, role := range roles {
+		for _, granted := range p.Roles {
+			if granted == role {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AccessTokenLifetime is how long an access token created by GenerateToken is valid.
+const AccessTokenLifetime = time.Hour * 24
+
+// twoFactorChallengeLifetime is how long a user has to enter the two-factor code after the password step.
+const twoFactorChallengeLifetime = time.Minute * 5
+
+// twoFactorChallengeClaim marks a JWT as a two-factor challenge token rather than an access token.
+const twoFactorChallengeClaim = "2fa_challenge"
+
+// appealTokenLifetime is how long a suspended or banned user has to file an appeal after logging in.
+const appealTokenLifetime = time.Hour
+
+// appealClaim marks a JWT as an appeal token rather than an access token.
+const appealClaim = "appeal"
+
+// GenerateToken returns an access token for the user, signed with the current key of the keyring.
+//
+// The session ID is sent as the "jti" claim, it lets the server revoke the token before it expires.
+//
+// Example usage:
+//
+//	token, err := auth.GenerateToken(user.ID, sessionID)
+//	if err != nil {
+//		utils.HandleError(w, err, http.StatusInternalServerError)
+//		return
+//	}
+func GenerateToken(userID uint, sessionID string) (string, error) {
+	keys, err := Keys()
+	if err != nil {
+		return "", err
+	}
+	return keys.Sign(jwt.MapClaims{
+		"userID": userID,
+		"jti":    sessionID,
+		"exp":    time.Now().Add(AccessTokenLifetime).Unix(),
+	})
+}
+
+// ParseAccessToken verifies an access token created by GenerateToken and returns its claims.
+//
+// Two-factor challenge tokens and tokens without a user ID are rejected.
+//
+// Example usage:
+//
+//	claims, err := auth.ParseAccessToken(r.Header.Get("Authorization"))
+//	if err != nil {
+//		// send unauthorized response
+//	}
+//	sessionID, _ := claims["jti"].(string)
+func ParseAccessToken(tokenString string) (jwt.MapClaims, error) {
+	if tokenString == "" {
+		return nil, errors.New("missing token")
+	}
+
+	keys, err := Keys()
+	if err != nil {
+		return nil, err
+	}
+	claims, err := keys.Parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Two-factor challenge tokens only grant access to the second login step, appeal tokens only to appeals
+	if _, isChallenge := claims[twoFactorChallengeClaim]; isChallenge {
+		return nil, errors.New("invalid token")
+	}
+	if _, isAppeal := claims[appealClaim]; isAppeal {
+		return nil, errors.New("invalid token")
+	}
+
+	if _, exists := claims["userID"]; !exists {
+		return nil, errors.New("user ID not found in token claims")
+	}
+
+	return claims, nil
+}
+
+// UserIDFromClaims returns the "userID" claim of a token as a uint.
+//
+// JSON numbers are decoded as float64, so the claim is accepted as any number type as long as it is
+// a positive whole number.
+func UserIDFromClaims(claims jwt.MapClaims) (uint, error) {
+	var id float64
+	switch v := claims["userID"].(type) {
+	case float64:
+		id = v
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, errors.New("invalid user ID in token claims")
+		}
+		id = f
+	case int:
+		id = float64(v)
+	case int64:
+		id = float64(v)
+	case uint:
+		id = float64(v)
+	case nil:
+		return 0, errors.New("user ID not found in token claims")
+	default:
+		return 0, fmt.Errorf("unsupported user ID type: %T", v)
+	}
+
+	if id <= 0 || id != math.Trunc(id) || id > math.MaxUint32 {
+		return 0, errors.New("invalid user ID in token claims")
+	}
+	return uint(id), nil
+}
+
+// GenerateTwoFactorChallengeToken returns a short-lived token that proves the user passed the password step
+// of a login and still has to provide a two-factor code.
+//
+// The token carries the "2fa_challenge" claim so ParseAccessToken never accepts it as an access token.
+//
+// Example usage:
+//
+//	challenge, err := auth.GenerateTwoFactorChallengeToken(user.ID)
+//	if err != nil {
+//		utils.HandleError(w, err, http.StatusInternalServerError)
+//		return
+//	}
+func GenerateTwoFactorChallengeToken(userID uint) (string, error) {
+	keys, err := Keys()
+	if err != nil {
+		return "", err
+	}
+	return keys.Sign(jwt.MapClaims{
+		"userID":                userID,
+		twoFactorChallengeClaim: true,
+		"exp":                   time.Now().Add(twoFactorChallengeLifetime).Unix(),
+	})
+}
+
+// ParseTwoFactorChallengeToken validates a token created by GenerateTwoFactorChallengeToken and returns its user ID.
+//
+// Example usage:
+//
+//	userID, err := auth.ParseTwoFactorChallengeToken(req.ChallengeToken)
+//	if err != nil {
+//		// the challenge is invalid or expired, the user has to log in again
+//	}
+func ParseTwoFactorChallengeToken(tokenString string) (uint, error) {
+	keys, err := Keys()
+	if err != nil {
+		return 0, err
+	}
+	claims, err := keys.Parse(tokenString)
+	if err != nil {
+		return 0, errors.New("invalid or expired two-factor challenge")
+	}
+	if isChallenge, _ := claims[twoFactorChallengeClaim].(bool); !isChallenge {
+		return 0, errors.New("invalid or expired two-factor challenge")
+	}
+
+	return UserIDFromClaims(claims)
+}
+
+// GenerateAppealToken returns a short-lived token that lets a suspended or banned user, who can't log in,
+// appeal the decision. It is handed out instead of an access token when such a user logs in.
+//
+// The token carries the "appeal" claim so ParseAccessToken never accepts it as an access token.
+func GenerateAppealToken(userID uint) (string, error) {
+	keys, err := Keys()
+	if err != nil {
+		return "", err
+	}
+	return keys.Sign(jwt.MapClaims{
+		"userID":    userID,
+		appealClaim: true,
+		"exp":       time.Now().Add(appealTokenLifetime).Unix(),
+	})
+}
+
+// ParseAppealToken validates a token created by GenerateAppealToken and returns its user ID.
+func ParseAppealToken(tokenString string) (uint, error) {
+	keys, err := Keys()
+	if err != nil {
+		return 0, err
+	}
+	claims, err := keys.Parse(tokenString)
+	if err != nil {
+		return 0, errors.New("invalid or expired appeal token")
+	}
+	if isAppeal, _ := claims[appealClaim].(bool); !isAppeal {
+		return 0, errors.New("invalid or expired appeal token")
+	}
+
+	return UserIDFromClaims(claims)
+}
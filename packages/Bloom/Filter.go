@@ -0,0 +1,140 @@
+package bloom
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+)
+
+// fileMagic identifies a serialized filter.
+var fileMagic = [4]byte{'G', 'B', 'F', '1'}
+
+// Filter is a bloom filter: Test never returns false for an added value, but may return true for a
+// value that was never added, with the false positive rate chosen in New.
+type Filter struct {
+	bits []uint64
+	m    uint64
+	k    uint32
+}
+
+// New returns an empty filter sized for n values at the given false positive rate.
+func New(n uint64, falsePositiveRate float64) *Filter {
+	if n == 0 {
+		n = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.001
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k == 0 {
+		k = 1
+	}
+	return &Filter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// Add inserts value into the filter.
+func (f *Filter) Add(value string) {
+	h1, h2 := hashes(value)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		pos := (h1 + i*h2) % f.m
+		f.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+// Test reports whether value may have been added to the filter.
+func (f *Filter) Test(value string) bool {
+	h1, h2 := hashes(value)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		pos := (h1 + i*h2) % f.m
+		if f.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteTo serializes the filter to w.
+func (f *Filter) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	header := make([]byte, 16)
+	copy(header, fileMagic[:])
+	binary.BigEndian.PutUint64(header[4:], f.m)
+	binary.BigEndian.PutUint32(header[12:], f.k)
+	if _, err := bw.Write(header); err != nil {
+		return 0, err
+	}
+	word := make([]byte, 8)
+	for _, bits := range f.bits {
+		binary.BigEndian.PutUint64(word, bits)
+		if _, err := bw.Write(word); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(header) + 8*len(f.bits)), bw.Flush()
+}
+
+// Read deserializes a filter written by WriteTo.
+func Read(r io.Reader) (*Filter, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, err
+	}
+	if [4]byte{header[0], header[1], header[2], header[3]} != fileMagic {
+		return nil, errors.New("bloom: not a bloom filter file")
+	}
+	m := binary.BigEndian.Uint64(header[4:])
+	k := binary.BigEndian.Uint32(header[12:])
+	if m == 0 || k == 0 {
+		return nil, errors.New("bloom: invalid filter header")
+	}
+
+	// The rest of the file has to hold exactly the words of m bits. It is read before the filter is
+	// allocated, so that a corrupt m can't make us allocate more than the file holds.
+	words := m / 64
+	if m%64 != 0 {
+		words++
+	}
+	if words > math.MaxInt64/8 {
+		return nil, errors.New("bloom: invalid filter header")
+	}
+	data, err := io.ReadAll(io.LimitReader(br, int64(words*8)+1))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) != words*8 {
+		return nil, errors.New("bloom: the filter size doesn't match its header")
+	}
+
+	f := &Filter{bits: make([]uint64, words), m: m, k: k}
+	for i := range f.bits {
+		f.bits[i] = binary.BigEndian.Uint64(data[i*8:])
+	}
+	return f, nil
+}
+
+// Load reads a filter from the file at path.
+func Load(path string) (*Filter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// hashes returns the two base hashes used for double hashing. h2 is odd, so it is never 0; the k
+// probes of a value still land on the same bit when h2 happens to be a multiple of m, which only
+// makes that value a little more likely to be a false positive.
+func hashes(value string) (uint64, uint64) {
+	a := fnv.New64a()
+	a.Write([]byte(value))
+	b := fnv.New64()
+	b.Write([]byte(value))
+	return a.Sum64(), b.Sum64() | 1
+}
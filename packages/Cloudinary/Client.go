@@ -5,6 +5,7 @@ import (
 	"fmt"
 	gongaCloudinary "gonga/contracts/Cloudinary"
 	"mime/multipart"
+	"strings"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api"
@@ -99,3 +100,27 @@ func (c *CloudinaryClient) DeleteFile(publicID string) (*gongaCloudinary.Cloudin
 		Message: "File deleted succesfully.",
 	}, nil
 }
+
+// PublicIDFromURL returns the public ID of an uploaded file from its delivery URL, for example
+// "abc" for "https://res.cloudinary.com/demo/image/upload/v1690000000/abc.jpg".
+func PublicIDFromURL(fileURL string) string {
+	_, path, found := strings.Cut(fileURL, "/upload/")
+	if !found {
+		return ""
+	}
+
+	// Everything after the version segment is the public ID, transformations come before it
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && segment[0] == 'v' && strings.Trim(segment[1:], "0123456789") == "" {
+			segments = segments[i+1:]
+			break
+		}
+	}
+
+	publicID := strings.Join(segments, "/")
+	if dot := strings.LastIndex(publicID, "."); dot > strings.LastIndex(publicID, "/") {
+		publicID = publicID[:dot]
+	}
+	return publicID
+}
@@ -0,0 +1,143 @@
+package contentfilter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Action is what happens to content that a rule matched. Actions are ordered by severity.
+type Action int
+
+const (
+	// Allow publishes the content.
+	Allow Action = iota
+	// Flag publishes the content and adds it to the moderation queue.
+	Flag
+	// Hold hides the content until a moderator reviews it.
+	Hold
+	// Reject refuses the content.
+	Reject
+)
+
+func (a Action) String() string {
+	switch a {
+	case Flag:
+		return "flag"
+	case Hold:
+		return "hold"
+	case Reject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+// ParseAction parses "flag", "hold" or "reject".
+func ParseAction(value string) (Action, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "allow":
+		return Allow, nil
+	case "flag":
+		return Flag, nil
+	case "hold":
+		return Hold, nil
+	case "reject":
+		return Reject, nil
+	default:
+		return Allow, fmt.Errorf("contentfilter: unknown action %q", value)
+	}
+}
+
+// Content is a post or comment about to be published.
+type Content struct {
+	// Type is "posts" or "comments".
+	Type string
+	// ID is the ID of the post or comment when it is edited, 0 when it is created.
+	ID       uint
+	AuthorID uint
+	Title    string
+	Body     string
+}
+
+// Text returns the title and body of the content.
+func (c Content) Text() string {
+	if c.Title == "" {
+		return c.Body
+	}
+	return c.Title + "\n" + c.Body
+}
+
+// Verdict is the decision of a rule about content.
+type Verdict struct {
+	Action Action
+	Rule   string
+	Reason string
+}
+
+// Rule checks content. It returns nil when the content passes.
+type Rule interface {
+	Check(ctx context.Context, content Content) (*Verdict, error)
+}
+
+// RuleFunc adapts a function to the Rule interface.
+type RuleFunc func(ctx context.Context, content Content) (*Verdict, error)
+
+// Check calls f.
+func (f RuleFunc) Check(ctx context.Context, content Content) (*Verdict, error) {
+	return f(ctx, content)
+}
+
+// Result is the outcome of a pipeline: the most severe action of the rules that matched, and their verdicts.
+type Result struct {
+	Action   Action
+	Verdicts []Verdict
+}
+
+// Reason joins the reasons of the verdicts with the result's action.
+func (r Result) Reason() string {
+	var reasons []string
+	for _, verdict := range r.Verdicts {
+		if verdict.Action == r.Action {
+			reasons = append(reasons, verdict.Reason)
+		}
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// Pipeline runs content through its rules in order.
+type Pipeline struct {
+	rules []Rule
+}
+
+// New returns a pipeline with the rules.
+func New(rules ...Rule) *Pipeline {
+	return &Pipeline{rules: rules}
+}
+
+// Use appends a rule to the pipeline. It must not be called while the pipeline checks content.
+func (p *Pipeline) Use(rule Rule) {
+	p.rules = append(p.rules, rule)
+}
+
+// Check runs the rules and returns the combined result. It stops at the first rule that rejects the content.
+func (p *Pipeline) Check(ctx context.Context, content Content) (Result, error) {
+	result := Result{Action: Allow}
+	for _, rule := range p.rules {
+		verdict, err := rule.Check(ctx, content)
+		if err != nil {
+			return result, err
+		}
+		if verdict == nil || verdict.Action == Allow {
+			continue
+		}
+		result.Verdicts = append(result.Verdicts, *verdict)
+		if verdict.Action > result.Action {
+			result.Action = verdict.Action
+		}
+		if result.Action == Reject {
+			break
+		}
+	}
+	return result, nil
+}
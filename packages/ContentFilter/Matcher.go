@@ -0,0 +1,136 @@
+package contentfilter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// substitutions maps characters used to disguise letters to the letter they stand for: digits and
+// symbols ("sh1t", "$pam") and Cyrillic letters that look like Latin ones.
+var substitutions = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b',
+	'@': 'a', '$': 's', '!': 'i', '|': 'l', '+': 't',
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j', 'ѕ': 's',
+}
+
+// Matcher finds words and phrases in text, also when they are disguised with look-alike characters,
+// punctuation or spaces between the letters ("b.a.d", "b a d"), or repeated letters ("baaad").
+// Words only match whole words, so "ass" does not match "class".
+type Matcher struct {
+	terms []matcherTerm
+}
+
+type matcherTerm struct {
+	term  string
+	words []*regexp.Regexp
+}
+
+// NewMatcher returns a matcher for the words and phrases. Empty entries are ignored.
+func NewMatcher(terms []string) *Matcher {
+	m := &Matcher{}
+	for _, term := range terms {
+		words := normalizeWords(term)
+		if len(words) == 0 {
+			continue
+		}
+		compiled := matcherTerm{term: strings.TrimSpace(term)}
+		for _, word := range words {
+			var pattern strings.Builder
+			pattern.WriteString("^")
+			for _, r := range word {
+				pattern.WriteString(regexp.QuoteMeta(string(r)))
+				pattern.WriteString("+")
+			}
+			pattern.WriteString("$")
+			compiled.words = append(compiled.words, regexp.MustCompile(pattern.String()))
+		}
+		m.terms = append(m.terms, compiled)
+	}
+	return m
+}
+
+// Empty reports whether the matcher has no terms. A nil matcher is empty.
+func (m *Matcher) Empty() bool {
+	return m == nil || len(m.terms) == 0
+}
+
+// Match returns the first term found in the text.
+func (m *Matcher) Match(text string) (string, bool) {
+	if m.Empty() {
+		return "", false
+	}
+
+	words := normalizeWords(text)
+	joined := joinSpacedLetters(words)
+	for _, term := range m.terms {
+		if containsTerm(words, term.words) || (len(term.words) == 1 && containsTerm(joined, term.words)) {
+			return term.term, true
+		}
+	}
+	return "", false
+}
+
+func containsTerm(words []string, term []*regexp.Regexp) bool {
+	for start := 0; start+len(term) <= len(words); start++ {
+		matched := true
+		for i, pattern := range term {
+			if !pattern.MatchString(words[start+i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeWords splits text into lowercase words, replaces look-alike characters and drops punctuation.
+func normalizeWords(text string) []string {
+	var words []string
+	for _, field := range strings.Fields(text) {
+		// Punctuation around a word is not part of it, "bad!" must not read as "badi"
+		field = strings.TrimRightFunc(field, func(r rune) bool { return r != '$' && unicode.IsPunct(r) })
+		field = strings.TrimLeftFunc(field, func(r rune) bool { return r != '$' && r != '@' && unicode.IsPunct(r) })
+
+		var word strings.Builder
+		for _, r := range strings.ToLower(field) {
+			if sub, ok := substitutions[r]; ok {
+				r = sub
+			}
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				word.WriteRune(r)
+			}
+		}
+		if word.Len() > 0 {
+			words = append(words, word.String())
+		}
+	}
+	return words
+}
+
+// joinSpacedLetters returns the words formed by runs of at least three single letters, "s p a m" is "spam".
+func joinSpacedLetters(words []string) []string {
+	var joined []string
+	var run strings.Builder
+	runLength := 0
+	flush := func() {
+		if runLength >= 3 {
+			joined = append(joined, run.String())
+		}
+		run.Reset()
+		runLength = 0
+	}
+	for _, word := range words {
+		if len([]rune(word)) == 1 {
+			run.WriteString(word)
+			runLength++
+			continue
+		}
+		flush()
+	}
+	flush()
+	return joined
+}
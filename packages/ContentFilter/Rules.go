@@ -0,0 +1,115 @@
+package contentfilter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// linkPattern matches web links with or without scheme.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+
+// BannedWords matches content that contains one of the banned words or phrases.
+type BannedWords struct {
+	Matcher *Matcher
+	Action  Action
+}
+
+// Check implements Rule.
+func (b BannedWords) Check(_ context.Context, content Content) (*Verdict, error) {
+	if _, ok := b.Matcher.Match(content.Text()); ok {
+		return &Verdict{Action: b.Action, Rule: "banned_words", Reason: "it contains a banned word"}, nil
+	}
+	return nil, nil
+}
+
+// LinkLimit matches content with more than Max links.
+type LinkLimit struct {
+	Max    int
+	Action Action
+}
+
+// Check implements Rule.
+func (l LinkLimit) Check(_ context.Context, content Content) (*Verdict, error) {
+	if links := len(linkPattern.FindAllStringIndex(content.Text(), -1)); links > l.Max {
+		return &Verdict{Action: l.Action, Rule: "links", Reason: fmt.Sprintf("it contains more than %d links", l.Max)}, nil
+	}
+	return nil, nil
+}
+
+// History looks up content published before.
+type History interface {
+	// CountDuplicates returns how many posts or comments of the same type the author published since
+	// the given time with the same body, without the content itself when it is edited.
+	CountDuplicates(ctx context.Context, content Content, since time.Time) (int, error)
+}
+
+// Duplicates matches content the author already published Limit times within Window, the usual
+// pattern of spam bots that post the same comment everywhere.
+type Duplicates struct {
+	History History
+	Limit   int
+	Window  time.Duration
+	Action  Action
+}
+
+// Check implements Rule.
+func (d Duplicates) Check(ctx context.Context, content Content) (*Verdict, error) {
+	if d.Limit <= 0 || content.Body == "" {
+		return nil, nil
+	}
+	count, err := d.History.CountDuplicates(ctx, content, time.Now().Add(-d.Window))
+	if err != nil {
+		return nil, err
+	}
+	if count >= d.Limit {
+		return &Verdict{Action: d.Action, Rule: "duplicates", Reason: "the same text was posted too many times"}, nil
+	}
+	return nil, nil
+}
+
+// Classifier scores how likely content is unwanted, for example by calling a spam detection service.
+type Classifier interface {
+	// Classify returns a score from 0, acceptable, to 1, certainly unwanted.
+	Classify(ctx context.Context, content Content) (float64, error)
+}
+
+// Classified matches content that the classifier scores at or above the thresholds. A threshold of 0 is disabled.
+//
+// Example usage:
+//
+//	services.UseContentRule(contentfilter.Classified{
+//		Name:            "spam",
+//		Classifier:      mySpamClassifier,
+//		FlagThreshold:   0.5,
+//		RejectThreshold: 0.95,
+//	})
+type Classified struct {
+	Name            string
+	Classifier      Classifier
+	FlagThreshold   float64
+	HoldThreshold   float64
+	RejectThreshold float64
+}
+
+// Check implements Rule.
+func (c Classified) Check(ctx context.Context, content Content) (*Verdict, error) {
+	score, err := c.Classifier.Classify(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+
+	var action Action
+	switch {
+	case c.RejectThreshold > 0 && score >= c.RejectThreshold:
+		action = Reject
+	case c.HoldThreshold > 0 && score >= c.HoldThreshold:
+		action = Hold
+	case c.FlagThreshold > 0 && score >= c.FlagThreshold:
+		action = Flag
+	default:
+		return nil, nil
+	}
+	return &Verdict{Action: action, Rule: c.Name, Reason: fmt.Sprintf("it was classified as %s", c.Name)}, nil
+}
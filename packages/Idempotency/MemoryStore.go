@@ -0,0 +1,70 @@
+package idempotency
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps the keys in memory, for a single server. Keys are lost on restart.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*memoryRecord
+	// sweepAt is when the expired keys are deleted next
+	sweepAt time.Time
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+// NewMemoryStore returns an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]*memoryRecord{}}
+}
+
+// Reserve claims the key for the request with the hash until the lease passes.
+func (s *MemoryStore) Reserve(key, requestHash string, lease time.Duration) (*Record, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.After(s.sweepAt) {
+		for k, record := range s.records {
+			if !now.Before(record.expiresAt) {
+				delete(s.records, k)
+			}
+		}
+		s.sweepAt = now.Add(time.Minute)
+	}
+
+	if record, ok := s.records[key]; ok && now.Before(record.expiresAt) {
+		existing := record.Record
+		return &existing, nil
+	}
+	s.records[key] = &memoryRecord{Record: Record{RequestHash: requestHash}, expiresAt: now.Add(lease)}
+	return nil, nil
+}
+
+// Complete stores the response of the request that claimed the key until ttl passes.
+func (s *MemoryStore) Complete(key string, response Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok {
+		record.Completed = true
+		record.Response = response
+		record.expiresAt = time.Now().Add(ttl)
+	}
+	return nil
+}
+
+// Release gives the key up so the request can be retried.
+func (s *MemoryStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
@@ -0,0 +1,37 @@
+package idempotency
+
+import (
+	"net/http"
+	"time"
+)
+
+// Response is the response of a request, stored to be replayed to retries of the request.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Record is the state of an idempotency key.
+type Record struct {
+	// RequestHash identifies the request that claimed the key, retries must send the same request
+	RequestHash string
+	// Completed is false while the request that claimed the key is being processed
+	Completed bool
+	Response  Response
+}
+
+// Store keeps the idempotency keys and the responses of their requests until they expire.
+//
+// Keys are scoped by the caller, a store treats them as opaque strings.
+type Store interface {
+	// Reserve claims the key for the request with the hash until the lease passes. It returns nil when
+	// the request claimed the key and the record of the key when another request claimed it before.
+	// The lease is short, a request that never completes, because its server crashed, doesn't block
+	// its retries for long.
+	Reserve(key, requestHash string, lease time.Duration) (*Record, error)
+	// Complete stores the response of the request that claimed the key and keeps it until ttl passes.
+	Complete(key string, response Response, ttl time.Duration) error
+	// Release gives the key up so the request can be retried, for example after a server error.
+	Release(key string) error
+}
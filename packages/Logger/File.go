@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File is a log file that rotates by size or by day.
+//
+// A file that rotates by size is renamed to name-<time>.ext when it reaches its maximum size, and
+// a new file is started. A file that rotates by day writes to name-<date>.ext and starts a new
+// file at midnight. Old files are deleted when there are more than MaxFiles of them, or when they
+// are older than MaxAge.
+type File struct {
+	// Path is the file that is written to, without the date when it rotates by day
+	Path string
+	// Daily starts a new file every day
+	Daily bool
+	// MaxSize is the size in bytes after which a new file is started, 0 never does
+	MaxSize int64
+	// MaxFiles is the number of old files that are kept, 0 keeps all of them
+	MaxFiles int
+	// MaxAge is how long old files are kept, 0 keeps them until there are more than MaxFiles
+	MaxAge time.Duration
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	// day is the date the open file of a daily log is for
+	day string
+}
+
+// Write writes to the log file, opening and rotating it as needed. The directory of the file is
+// created when it doesn't exist.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	if f.file != nil {
+		if f.Daily && now.Format("2006-01-02") != f.day {
+			f.closeFile()
+		} else if !f.Daily && f.MaxSize > 0 && f.size+int64(len(p)) > f.MaxSize && f.size > 0 {
+			if err := f.rotate(now); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if f.file == nil {
+		if err := f.open(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file. The next write opens it again.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closeFile()
+}
+
+func (f *File) closeFile() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the file the log writes to now, and deletes the old files when it starts a new one.
+func (f *File) open(now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return err
+	}
+
+	path := f.Path
+	if f.Daily {
+		f.day = now.Format("2006-01-02")
+		path = f.name(f.day)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	if f.Daily {
+		f.prune(now)
+	}
+	return nil
+}
+
+// rotate moves the full file aside and starts a new one.
+func (f *File) rotate(now time.Time) error {
+	if err := f.closeFile(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Path, f.name(now.Format("2006-01-02T15-04-05.000"))); err != nil {
+		return err
+	}
+	f.prune(now)
+	return nil
+}
+
+// name returns the path of the file with the suffix added to its name, before its extension.
+func (f *File) name(suffix string) string {
+	ext := filepath.Ext(f.Path)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.Path, ext), suffix, ext)
+}
+
+// prune deletes the old files beyond MaxFiles and those older than MaxAge. The open file of a
+// daily log is never deleted.
+func (f *File) prune(now time.Time) {
+	if f.MaxFiles <= 0 && f.MaxAge <= 0 {
+		return
+	}
+	ext := filepath.Ext(f.Path)
+	matches, err := filepath.Glob(strings.TrimSuffix(f.Path, ext) + "-*" + ext)
+	if err != nil {
+		return
+	}
+
+	current := ""
+	if f.Daily {
+		current = f.name(f.day)
+	}
+	type oldFile struct {
+		path    string
+		modTime time.Time
+	}
+	var old []oldFile
+	for _, match := range matches {
+		if match == current {
+			continue
+		}
+		if info, err := os.Stat(match); err == nil && info.Mode().IsRegular() {
+			old = append(old, oldFile{path: match, modTime: info.ModTime()})
+		}
+	}
+	// Newest first
+	sort.Slice(old, func(i, j int) bool { return old[i].modTime.After(old[j].modTime) })
+
+	for i, file := range old {
+		if (f.MaxFiles > 0 && i >= f.MaxFiles) || (f.MaxAge > 0 && now.Sub(file.modTime) > f.MaxAge) {
+			os.Remove(file.path)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gonga/config"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger writes the logs of GORM to a logger. Failed queries are logged at the error level and
+// slow queries at the warn level, every other query at the debug level when the level is Info.
+type GormLogger struct {
+	Logger             *slog.Logger
+	Level              gormlogger.LogLevel
+	SlowQueryThreshold time.Duration
+}
+
+// NewGormLogger returns a GORM logger that writes to l, configured by DB_LOG_LEVEL and DB_SLOW_QUERY_MS.
+func NewGormLogger(l *slog.Logger, cfg *config.LoggerConfig) GormLogger {
+	level := gormlogger.Warn
+	switch cfg.QueryLevel {
+	case "silent":
+		level = gormlogger.Silent
+	case "error":
+		level = gormlogger.Error
+	case "info":
+		level = gormlogger.Info
+	}
+	return GormLogger{Logger: l, Level: level, SlowQueryThreshold: cfg.SlowQueryThreshold}
+}
+
+func (g GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	g.Level = level
+	return g
+}
+
+func (g GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if g.Level >= gormlogger.Info {
+		g.Logger.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (g GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if g.Level >= gormlogger.Warn {
+		g.Logger.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (g GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if g.Level >= gormlogger.Error {
+		g.Logger.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (g GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if g.Level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	// Not finding a record is an expected result, not a failure
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && g.Level >= gormlogger.Error:
+		sql, rows := fc()
+		g.Logger.ErrorContext(ctx, "query failed", "error", err, "sql", sql, "rows", rows, "duration_ms", milliseconds(elapsed))
+	case g.SlowQueryThreshold > 0 && elapsed > g.SlowQueryThreshold && g.Level >= gormlogger.Warn:
+		sql, rows := fc()
+		g.Logger.WarnContext(ctx, "slow query", "sql", sql, "rows", rows, "duration_ms", milliseconds(elapsed))
+	case g.Level >= gormlogger.Info:
+		sql, rows := fc()
+		g.Logger.DebugContext(ctx, "query", "sql", sql, "rows", rows, "duration_ms", milliseconds(elapsed))
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,219 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gonga/config"
+	"gonga/utils"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+var (
+	defaultLogger *slog.Logger
+	defaultMu     sync.Mutex
+
+	// files are the open log files by path, channels that write to the same file share it
+	files   = map[string]*File{}
+	filesMu sync.Mutex
+)
+
+// Default returns the logger of the application, which writes to the LOG_CHANNEL channel unless
+// SetDefault replaced it. When the channel is misconfigured it logs the error and writes to stderr,
+// so the application still starts.
+func Default() *slog.Logger {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultLogger == nil {
+		cfg := config.LoadLoggerConfig()
+		l, err := New(cfg, cfg.Default)
+		if err != nil {
+			l = slog.New(&contextHandler{Handler: slog.NewTextHandler(os.Stderr, nil)})
+			l.Error("failed to create the logger, logging to stderr", "error", err)
+		}
+		defaultLogger = l
+	}
+	return defaultLogger
+}
+
+// SetDefault replaces the logger of the application.
+func SetDefault(l *slog.Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = l
+}
+
+// Channel returns a logger that writes to the named channel of the logging config, for example
+// "daily" to keep some logs apart from the default channel.
+func Channel(name string) (*slog.Logger, error) {
+	return New(config.LoadLoggerConfig(), name)
+}
+
+// New returns a logger that writes to the named channel of cfg. The log lines of the *Context
+// methods of the logger carry the ID of the request of the context.
+func New(cfg *config.LoggerConfig, channel string) (*slog.Logger, error) {
+	handler, err := newHandler(cfg, channel, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(&contextHandler{Handler: handler}), nil
+}
+
+// FromContext returns the default logger with the request ID of ctx added to every line, also for
+// the methods that don't take a context.
+//
+// Example usage:
+//
+//	logger.FromContext(r.Context()).Warn("the avatar could not be resized", "error", err)
+func FromContext(ctx context.Context) *slog.Logger {
+	l := Default()
+	id := utils.RequestID(ctx)
+	if h, ok := l.Handler().(*contextHandler); ok && id != "" {
+		return slog.New(&contextHandler{Handler: h.Handler, requestID: id})
+	}
+	return l
+}
+
+// Close closes the log files. Lines logged afterwards open them again.
+func Close() error {
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	var errs []error
+	for _, file := range files {
+		errs = append(errs, file.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func newHandler(cfg *config.LoggerConfig, name string, seen map[string]bool) (slog.Handler, error) {
+	channel, ok := cfg.Channels[name]
+	if !ok {
+		return nil, fmt.Errorf("logger: unknown log channel %q", name)
+	}
+	if seen[name] {
+		return nil, fmt.Errorf("logger: log channel %q contains itself", name)
+	}
+	seen[name] = true
+	defer delete(seen, name)
+
+	level := channel.Level
+	if level == "" {
+		level = cfg.Level
+	}
+	var options slog.HandlerOptions
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("logger: invalid level %q of log channel %q", level, name)
+	}
+	options.Level = lvl
+
+	var w io.Writer
+	switch channel.Driver {
+	case "stack":
+		var handlers multiHandler
+		for _, child := range channel.Channels {
+			handler, err := newHandler(cfg, child, seen)
+			if err != nil {
+				return nil, err
+			}
+			handlers = append(handlers, handler)
+		}
+		return handlers, nil
+	case "single", "daily":
+		w = openFile(channel)
+	case "stderr":
+		w = os.Stderr
+	default:
+		return nil, fmt.Errorf("logger: unknown driver %q of log channel %q", channel.Driver, name)
+	}
+
+	if channel.Format == "json" {
+		return slog.NewJSONHandler(w, &options), nil
+	}
+	return slog.NewTextHandler(w, &options), nil
+}
+
+// openFile returns the log file of the channel, shared with the other channels that write to it.
+func openFile(channel config.LogChannelConfig) *File {
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	if file, ok := files[channel.Path]; ok {
+		return file
+	}
+	file := &File{
+		Path:     channel.Path,
+		Daily:    channel.Driver == "daily",
+		MaxSize:  int64(channel.MaxSize) * 1024 * 1024,
+		MaxFiles: channel.MaxFiles,
+		MaxAge:   channel.MaxAge,
+	}
+	files[channel.Path] = file
+	return file
+}
+
+// contextHandler adds the ID of the request to the log lines.
+type contextHandler struct {
+	slog.Handler
+	// requestID is used when the context of a line has no request ID
+	requestID string
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	id := utils.RequestID(ctx)
+	if id == "" {
+		id = h.requestID
+	}
+	if id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), requestID: h.requestID}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), requestID: h.requestID}
+}
+
+// multiHandler writes the log lines to all its handlers, the stack channel.
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range m {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
@@ -0,0 +1,147 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// StateCookie is the name of the cookie that ties a login to the browser that started it.
+const StateCookie = "oauth_state"
+
+var (
+	// ErrInvalidState is returned when the state of a callback is unknown, expired, was already used
+	// or belongs to a login that another browser started.
+	ErrInvalidState = errors.New("invalid or expired state")
+	// ErrExchange is returned when the provider didn't exchange the authorization code.
+	ErrExchange = errors.New("failed to exchange the authorization code")
+	// ErrIdentity is returned when the identity the provider returned can't be verified.
+	ErrIdentity = errors.New("failed to verify the identity")
+)
+
+// PendingLogin is a login that was started at a provider and waits for its callback. It holds the
+// PKCE code verifier and OpenID Connect nonce that belong to the state parameter.
+type PendingLogin struct {
+	State        string
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+}
+
+// StateStore keeps the pending logins between the redirect to the provider and the callback.
+type StateStore interface {
+	Save(login PendingLogin) error
+	// Consume deletes the pending login of the state and returns it, so that every state can only
+	// be used once. It returns ErrInvalidState when there is none.
+	Consume(provider, state string) (*PendingLogin, error)
+}
+
+// Begin starts a login at the provider. It stores a new state, PKCE verifier and nonce, and returns
+// the URL of the provider's consent page and the pending login. The caller sets the state cookie
+// with SetStateCookie so that only the same browser can finish the login.
+func Begin(provider Provider, store StateStore, lifetime time.Duration) (string, *PendingLogin, error) {
+	state, err := GenerateState()
+	if err != nil {
+		return "", nil, err
+	}
+	nonce, err := GenerateState()
+	if err != nil {
+		return "", nil, err
+	}
+	verifier, err := GenerateCodeVerifier()
+	if err != nil {
+		return "", nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(state, CodeChallengeS256(verifier), nonce)
+	if err != nil {
+		return "", nil, err
+	}
+
+	login := PendingLogin{
+		State:        state,
+		Provider:     provider.Name(),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(lifetime),
+	}
+	if err := store.Save(login); err != nil {
+		return "", nil, err
+	}
+	return authURL, &login, nil
+}
+
+// Complete finishes a login with the state and authorization code of the callback. The binding is the
+// value of the state cookie the callback request carries, it has to belong to the state before the
+// state is consumed. The code is exchanged with the PKCE verifier of the state and the identity is
+// verified against its nonce.
+func Complete(ctx context.Context, provider Provider, store StateStore, state, binding, code string) (*Identity, error) {
+	if state == "" || subtle.ConstantTimeCompare([]byte(binding), []byte(stateBinding(state))) != 1 {
+		return nil, ErrInvalidState
+	}
+	login, err := store.Consume(provider.Name(), state)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(login.ExpiresAt) {
+		return nil, ErrInvalidState
+	}
+
+	token, err := provider.Exchange(ctx, code, login.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	identity, err := provider.Identity(ctx, token, login.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIdentity, err)
+	}
+	return identity, nil
+}
+
+// SetStateCookie sets the cookie that ties the login to the browser. It holds a hash of the state, is
+// hidden from scripts and is only sent on top-level navigations from other sites, like the redirect
+// back from the provider.
+func SetStateCookie(w http.ResponseWriter, login *PendingLogin, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     StateCookie,
+		Value:    stateBinding(login.State),
+		Path:     "/",
+		Expires:  login.ExpiresAt,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearStateCookie deletes the state cookie once the login is finished.
+func ClearStateCookie(w http.ResponseWriter, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     StateCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// StateCookieValue returns the value of the state cookie of the request, or an empty string.
+func StateCookieValue(r *http.Request) string {
+	cookie, err := r.Cookie(StateCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func stateBinding(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
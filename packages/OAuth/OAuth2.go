@@ -0,0 +1,176 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gonga/config"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// httpTimeout bounds every request made to a provider.
+const httpTimeout = 10 * time.Second
+
+// OAuth2Provider implements the authorization-code flow with PKCE against a plain OAuth2 provider
+// and reads the user's identity from its userinfo endpoint.
+type OAuth2Provider struct {
+	Config     config.OAuthProviderConfig
+	HTTPClient *http.Client
+}
+
+// NewOAuth2Provider returns a provider for the endpoints in cfg.
+func NewOAuth2Provider(cfg config.OAuthProviderConfig) *OAuth2Provider {
+	return &OAuth2Provider{
+		Config:     cfg,
+		HTTPClient: &http.Client{Timeout: httpTimeout},
+	}
+}
+
+func (p *OAuth2Provider) Name() string {
+	return p.Config.Name
+}
+
+func (p *OAuth2Provider) AuthCodeURL(state, codeChallenge, nonce string) (string, error) {
+	return buildAuthCodeURL(p.Config.AuthURL, p.Config, state, codeChallenge, nonce)
+}
+
+func (p *OAuth2Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	return exchangeCode(ctx, p.HTTPClient, p.Config.TokenURL, p.Config, code, codeVerifier)
+}
+
+func (p *OAuth2Provider) Identity(ctx context.Context, token *Token, _ string) (*Identity, error) {
+	return fetchUserInfo(ctx, p.HTTPClient, p.Config.UserInfoURL, token)
+}
+
+func buildAuthCodeURL(endpoint string, cfg config.OAuthProviderConfig, state, codeChallenge, nonce string) (string, error) {
+	authURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", cfg.ClientID)
+	query.Set("redirect_uri", cfg.RedirectURL)
+	query.Set("scope", strings.Join(cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	if nonce != "" {
+		query.Set("nonce", nonce)
+	}
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+func exchangeCode(ctx context.Context, client *http.Client, endpoint string, cfg config.OAuthProviderConfig, code, codeVerifier string) (*Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectURL)
+	form.Set("client_id", cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if cfg.ClientSecret != "" {
+		form.Set("client_secret", cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token Token
+	if err := doJSON(client, req, &token); err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token endpoint returned no access token")
+	}
+	return &token, nil
+}
+
+func fetchUserInfo(ctx context.Context, client *http.Client, endpoint string, token *Token) (*Identity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var info struct {
+		Subject       json.RawMessage `json:"sub"`
+		ID            json.RawMessage `json:"id"`
+		Email         string          `json:"email"`
+		EmailVerified interface{}     `json:"email_verified"`
+		Name          string          `json:"name"`
+		Picture       string          `json:"picture"`
+	}
+	if err := doJSON(client, req, &info); err != nil {
+		return nil, fmt.Errorf("failed to fetch user info: %w", err)
+	}
+
+	subject := rawID(info.Subject)
+	if subject == "" {
+		subject = rawID(info.ID)
+	}
+	if subject == "" {
+		return nil, errors.New("user info has no subject")
+	}
+
+	return &Identity{
+		Subject:       subject,
+		Email:         info.Email,
+		EmailVerified: isTrue(info.EmailVerified),
+		Name:          info.Name,
+		Picture:       info.Picture,
+	}, nil
+}
+
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, req.URL.Host)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// rawID accepts both string and numeric identifiers, some providers return numeric user ids.
+func rawID(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+// isTrue accepts both booleans and "true" strings, some providers encode email_verified as a string.
+func isTrue(v interface{}) bool {
+	switch value := v.(type) {
+	case bool:
+		return value
+	case string:
+		return strings.EqualFold(value, "true")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,31 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// GenerateCodeVerifier returns a random PKCE code verifier (RFC 7636 section 4.1).
+func GenerateCodeVerifier() (string, error) {
+	return randomURLSafe(32)
+}
+
+// CodeChallengeS256 derives the S256 code challenge of a PKCE code verifier.
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// GenerateState returns a random value for the state or nonce parameter.
+func GenerateState() (string, error) {
+	return randomURLSafe(24)
+}
+
+func randomURLSafe(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
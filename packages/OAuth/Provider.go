@@ -0,0 +1,137 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gonga/config"
+	"strings"
+	"sync"
+)
+
+// Provider is an identity provider that can be used for social login.
+//
+// The built-in providers cover OpenID Connect (discovery, ID token verification) and plain
+// OAuth2 with a userinfo endpoint. Providers with a non-standard API can be plugged in with Register.
+type Provider interface {
+	// Name returns the configured name of the provider, e.g. "google".
+	Name() string
+	// AuthCodeURL returns the URL of the provider's consent page for the authorization-code flow.
+	AuthCodeURL(state, codeChallenge, nonce string) (string, error)
+	// Exchange trades an authorization code and its PKCE verifier for tokens.
+	Exchange(ctx context.Context, code, codeVerifier string) (*Token, error)
+	// Identity returns the identity of the user the tokens were issued for.
+	Identity(ctx context.Context, token *Token, nonce string) (*Identity, error)
+}
+
+// Token is the response of the provider's token endpoint.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// Identity is the user information returned by a provider.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// LinkableEmail returns the normalized email address of the identity when the provider verified it.
+// Only verified addresses may link a provider identity to an existing account, otherwise anyone could
+// take over an account by registering its address at a provider that doesn't verify addresses.
+func (i *Identity) LinkableEmail() (string, bool) {
+	email := strings.ToLower(strings.TrimSpace(i.Email))
+	if email == "" || !i.EmailVerified {
+		return "", false
+	}
+	return email, true
+}
+
+// Factory creates a provider from its configuration.
+type Factory func(cfg config.OAuthProviderConfig) (Provider, error)
+
+var ErrUnknownProvider = errors.New("unknown oauth provider")
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{}
+)
+
+// Register makes a custom provider implementation available under the given name.
+// It takes precedence over the built-in OpenID Connect and OAuth2 providers.
+//
+// Example usage:
+//
+//	oauth.Register("github", func(cfg config.OAuthProviderConfig) (oauth.Provider, error) {
+//	    return NewGithubProvider(cfg), nil
+//	})
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[name] = factory
+}
+
+// NewProvider creates the provider described by cfg.
+//
+// A factory registered under the provider name is used first. Otherwise an OpenID Connect
+// provider is created when an issuer is configured, and a plain OAuth2 provider when not.
+func NewProvider(cfg config.OAuthProviderConfig) (Provider, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[cfg.Name]
+	factoriesMu.RUnlock()
+	if ok {
+		return factory(cfg)
+	}
+
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("oauth provider %q has no client id", cfg.Name)
+	}
+	if cfg.Issuer != "" {
+		return NewOIDCProvider(cfg), nil
+	}
+	if cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "" {
+		return nil, fmt.Errorf("oauth provider %q needs an issuer or auth, token and userinfo urls", cfg.Name)
+	}
+	return NewOAuth2Provider(cfg), nil
+}
+
+// Manager lazily creates and caches the configured providers.
+type Manager struct {
+	configs   map[string]config.OAuthProviderConfig
+	mu        sync.Mutex
+	providers map[string]Provider
+}
+
+// NewManager returns a Manager for the providers in the auth config.
+func NewManager(authConfig *config.AuthConfig) *Manager {
+	return &Manager{
+		configs:   authConfig.Providers,
+		providers: map[string]Provider{},
+	}
+}
+
+// Provider returns the provider with the given name.
+func (m *Manager) Provider(name string) (Provider, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if provider, ok := m.providers[name]; ok {
+		return provider, nil
+	}
+	cfg, ok := m.configs[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	provider, err := NewProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	m.providers[name] = provider
+	return provider, nil
+}
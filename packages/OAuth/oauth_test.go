@@ -0,0 +1,362 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"gonga/config"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testIssuer is a local OpenID Connect provider. It serves the discovery document, the JWKS, the
+// token endpoint and a userinfo endpoint, and hands out authorization codes with authorize.
+type testIssuer struct {
+	*httptest.Server
+	t   *testing.T
+	key *rsa.PrivateKey
+
+	mu sync.Mutex
+	// codes are the issued authorization codes and the PKCE challenge and nonce they were issued for
+	codes map[string]testGrant
+	// claims override the claims of the next ID tokens
+	claims jwt.MapClaims
+	// exchanges counts the requests to the token endpoint
+	exchanges int
+}
+
+type testGrant struct {
+	challenge string
+	nonce     string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &testIssuer{t: t, key: key, codes: map[string]testGrant{}, claims: jwt.MapClaims{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/authorize",
+			"token_endpoint":         issuer.URL + "/token",
+			"userinfo_endpoint":      issuer.URL + "/userinfo",
+			"jwks_uri":               issuer.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []map[string]string{{
+			"kid": "test",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", issuer.token)
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":             12345,
+			"email":          "Someone@Example.com",
+			"email_verified": "true",
+			"name":           "Some One",
+		})
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+// authorize plays the consent page: it returns a code for the authorization URL of Begin.
+func (i *testIssuer) authorize(authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		i.t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		i.t.Fatalf("authorization URL without an S256 code challenge: %s", authURL)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	code := "code-" + query.Get("state")
+	i.codes[code] = testGrant{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	return code
+}
+
+func (i *testIssuer) token(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.exchanges++
+
+	grant, ok := i.codes[r.PostFormValue("code")]
+	// Codes can only be exchanged once, and only with the verifier of their challenge
+	delete(i.codes, r.PostFormValue("code"))
+	if !ok || CodeChallengeS256(r.PostFormValue("code_verifier")) != grant.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":            i.URL,
+		"aud":            "client",
+		"sub":            "subject-1",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          grant.nonce,
+		"email":          "someone@example.com",
+		"email_verified": true,
+	}
+	for name, value := range i.claims {
+		claims[name] = value
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = "test"
+	signed, err := idToken.SignedString(i.key)
+	if err != nil {
+		i.t.Fatal(err)
+	}
+	writeJSON(w, http.StatusOK, Token{AccessToken: "access-token", TokenType: "Bearer", IDToken: signed})
+}
+
+func (i *testIssuer) provider() Provider {
+	return NewOIDCProvider(config.OAuthProviderConfig{
+		Name:        "test",
+		ClientID:    "client",
+		Issuer:      i.URL,
+		RedirectURL: "http://localhost/v1/auth/test/callback",
+		Scopes:      []string{"openid", "email"},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// memoryStates is a StateStore in memory.
+type memoryStates struct {
+	mu     sync.Mutex
+	logins map[string]PendingLogin
+}
+
+func newMemoryStates() *memoryStates {
+	return &memoryStates{logins: map[string]PendingLogin{}}
+}
+
+func (s *memoryStates) Save(login PendingLogin) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logins[login.Provider+" "+login.State] = login
+	return nil
+}
+
+func (s *memoryStates) Consume(provider, state string) (*PendingLogin, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	login, ok := s.logins[provider+" "+state]
+	if !ok {
+		return nil, ErrInvalidState
+	}
+	delete(s.logins, provider+" "+state)
+	return &login, nil
+}
+
+// begin starts a login and returns the code the provider issued and the state cookie of the browser.
+func begin(t *testing.T, issuer *testIssuer, provider Provider, store StateStore, lifetime time.Duration) (*PendingLogin, string, string) {
+	t.Helper()
+	authURL, login, err := Begin(provider, store, lifetime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	SetStateCookie(recorder, login, true)
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != StateCookie || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Fatalf("unexpected state cookie: %+v", cookies)
+	}
+	return login, issuer.authorize(authURL), cookies[0].Value
+}
+
+func TestOIDCLogin(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider, store := issuer.provider(), newMemoryStates()
+	login, code, cookie := begin(t, issuer, provider, store, time.Minute)
+
+	identity, err := Complete(context.Background(), provider, store, login.State, cookie, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Subject != "subject-1" || identity.Email != "someone@example.com" || !identity.EmailVerified {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+}
+
+func TestExchangeRequiresTheCodeVerifier(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := issuer.provider()
+	verifier, _ := GenerateCodeVerifier()
+	authURL, err := provider.AuthCodeURL("state", CodeChallengeS256(verifier), "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := issuer.authorize(authURL)
+
+	other, _ := GenerateCodeVerifier()
+	if _, err := provider.Exchange(context.Background(), code, other); err == nil {
+		t.Fatal("the code was exchanged with another verifier")
+	}
+}
+
+func TestCompleteRejectsAnotherNonce(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.claims["nonce"] = "another-nonce"
+	provider, store := issuer.provider(), newMemoryStates()
+	login, code, cookie := begin(t, issuer, provider, store, time.Minute)
+
+	_, err := Complete(context.Background(), provider, store, login.State, cookie, code)
+	if !errors.Is(err, ErrIdentity) {
+		t.Fatalf("expected ErrIdentity, got %v", err)
+	}
+}
+
+func TestCompleteRejectsATokenOfAnotherClient(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.claims["aud"] = "another-client"
+	provider, store := issuer.provider(), newMemoryStates()
+	login, code, cookie := begin(t, issuer, provider, store, time.Minute)
+
+	_, err := Complete(context.Background(), provider, store, login.State, cookie, code)
+	if !errors.Is(err, ErrIdentity) {
+		t.Fatalf("expected ErrIdentity, got %v", err)
+	}
+}
+
+func TestStateCanOnlyBeUsedOnce(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider, store := issuer.provider(), newMemoryStates()
+	login, code, cookie := begin(t, issuer, provider, store, time.Minute)
+
+	if _, err := Complete(context.Background(), provider, store, login.State, cookie, code); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Complete(context.Background(), provider, store, login.State, cookie, code)
+	if !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected ErrInvalidState on replay, got %v", err)
+	}
+	if issuer.exchanges != 1 {
+		t.Fatalf("the replay reached the token endpoint, %d exchanges", issuer.exchanges)
+	}
+}
+
+func TestExpiredStateIsRejected(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider, store := issuer.provider(), newMemoryStates()
+	login, code, cookie := begin(t, issuer, provider, store, -time.Second)
+
+	_, err := Complete(context.Background(), provider, store, login.State, cookie, code)
+	if !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected ErrInvalidState, got %v", err)
+	}
+	if issuer.exchanges != 0 {
+		t.Fatal("an expired state reached the token endpoint")
+	}
+}
+
+func TestStateIsBoundToTheBrowser(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider, store := issuer.provider(), newMemoryStates()
+	// The attacker starts a login and sends the callback URL to the victim, whose browser has another
+	// state cookie or none
+	login, code, cookie := begin(t, issuer, provider, store, time.Minute)
+	_, _, victimCookie := begin(t, issuer, provider, store, time.Minute)
+
+	for _, binding := range []string{"", victimCookie} {
+		_, err := Complete(context.Background(), provider, store, login.State, binding, code)
+		if !errors.Is(err, ErrInvalidState) {
+			t.Fatalf("expected ErrInvalidState with cookie %q, got %v", binding, err)
+		}
+	}
+
+	// The rejected callbacks didn't consume the state
+	if _, err := Complete(context.Background(), provider, store, login.State, cookie, code); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOAuth2UserInfo(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := NewOAuth2Provider(config.OAuthProviderConfig{
+		Name:        "plain",
+		ClientID:    "client",
+		AuthURL:     issuer.URL + "/authorize",
+		TokenURL:    issuer.URL + "/token",
+		UserInfoURL: issuer.URL + "/userinfo",
+	})
+	store := newMemoryStates()
+	login, code, cookie := begin(t, issuer, provider, store, time.Minute)
+
+	identity, err := Complete(context.Background(), provider, store, login.State, cookie, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Numeric ids and string booleans are accepted
+	if identity.Subject != "12345" || !identity.EmailVerified {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+	if email, ok := identity.LinkableEmail(); !ok || email != "someone@example.com" {
+		t.Fatalf("expected the normalized email to be linkable, got %q %v", email, ok)
+	}
+}
+
+func TestLinkableEmail(t *testing.T) {
+	tests := []struct {
+		identity Identity
+		email    string
+		ok       bool
+	}{
+		{Identity{Email: " Someone@Example.com ", EmailVerified: true}, "someone@example.com", true},
+		{Identity{Email: "someone@example.com", EmailVerified: false}, "", false},
+		{Identity{Email: "", EmailVerified: true}, "", false},
+	}
+	for _, test := range tests {
+		email, ok := test.identity.LinkableEmail()
+		if email != test.email || ok != test.ok {
+			t.Errorf("LinkableEmail(%+v) = %q, %v, want %q, %v", test.identity, email, ok, test.email, test.ok)
+		}
+	}
+}
+
+func TestUnverifiedEmailIsNotLinkable(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.claims["email_verified"] = false
+	provider, store := issuer.provider(), newMemoryStates()
+	login, code, cookie := begin(t, issuer, provider, store, time.Minute)
+
+	identity, err := Complete(context.Background(), provider, store, login.State, cookie, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := identity.LinkableEmail(); ok {
+		t.Fatal("an unverified email address can be linked to an account")
+	}
+}
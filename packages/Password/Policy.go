@@ -0,0 +1,150 @@
+package password
+
+import (
+	"errors"
+	"gonga/config"
+	bloom "gonga/packages/Bloom"
+	"gonga/utils"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minSimilarityLength is the shortest username or email name that is checked for similarity.
+// Shorter values would reject too many unrelated passwords.
+const minSimilarityLength = 3
+
+// Violation is the first password rule a password fails. Rule is a key of utils.Validation["password"].
+type Violation struct {
+	Rule  string
+	Param string
+}
+
+// Policy checks new passwords against the configured strength rules and breached password list.
+type Policy struct {
+	Config   config.PasswordConfig
+	Breached *bloom.Filter
+}
+
+var (
+	defaultPolicy     *Policy
+	defaultPolicyOnce sync.Once
+)
+
+// NewPolicy returns a policy for cfg. The breached password filter is loaded from cfg.BreachedListPath;
+// when the file cannot be read the breached password check is skipped and a warning is logged.
+func NewPolicy(cfg *config.PasswordConfig) *Policy {
+	policy := &Policy{Config: *cfg}
+	if cfg.BreachedListPath != "" {
+		filter, err := bloom.Load(cfg.BreachedListPath)
+		if err != nil {
+			log.Printf("breached password check disabled: %v", err)
+		} else {
+			policy.Breached = filter
+		}
+	}
+	return policy
+}
+
+// Default returns the policy of the application config. It is created on first use.
+func Default() *Policy {
+	defaultPolicyOnce.Do(func() {
+		defaultPolicy = NewPolicy(config.LoadPasswordConfig())
+	})
+	return defaultPolicy
+}
+
+// Check returns the first rule the password fails, or nil when it is accepted.
+//
+// identifiers are values the password must not resemble, such as the username and email address.
+func (p *Policy) Check(password string, identifiers ...string) *Violation {
+	if utf8.RuneCountInString(password) < p.Config.MinLength {
+		return &Violation{Rule: "min", Param: strconv.Itoa(p.Config.MinLength)}
+	}
+
+	var hasLetter, hasUpper, hasLower, hasNumber, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsLetter(char):
+			hasLetter = true
+			hasUpper = hasUpper || unicode.IsUpper(char)
+			hasLower = hasLower || unicode.IsLower(char)
+		case unicode.IsNumber(char):
+			hasNumber = true
+		case !unicode.IsSpace(char):
+			hasSymbol = true
+		}
+	}
+	switch {
+	case p.Config.RequireLetters && !hasLetter:
+		return &Violation{Rule: "letters"}
+	case p.Config.RequireMixedCase && !(hasUpper && hasLower):
+		return &Violation{Rule: "mixed"}
+	case p.Config.RequireNumbers && !hasNumber:
+		return &Violation{Rule: "numbers"}
+	case p.Config.RequireSymbols && !hasSymbol:
+		return &Violation{Rule: "symbols"}
+	}
+
+	if p.Config.CheckSimilarity {
+		if other, ok := similarTo(password, identifiers); ok {
+			return &Violation{Rule: "similar", Param: other}
+		}
+	}
+
+	if p.Breached != nil && p.Breached.Test(password) {
+		return &Violation{Rule: "uncompromised"}
+	}
+
+	return nil
+}
+
+// ValidateRequest checks password against the default policy and, when it fails, sends a 400 response
+// in the same format as utils.ValidateRequest. attribute is the name of the password field.
+//
+// Example usage:
+//
+//	if err := password.ValidateRequest(w, "Password", req.Password, user.Username, user.Email); err != nil {
+//		return
+//	}
+func ValidateRequest(w http.ResponseWriter, attribute, password string, identifiers ...string) error {
+	violation := Default().Check(password, identifiers...)
+	if violation == nil {
+		return nil
+	}
+
+	message := utils.PasswordValidationMessage(violation.Rule, attribute, violation.Param)
+	utils.JSONResponse(w, http.StatusBadRequest, utils.ErrorResponse{
+		Errors: map[string]string{attribute: message},
+	})
+	return errors.New(message)
+}
+
+// similarTo reports whether the password contains, or is contained in, one of the identifiers.
+// For email addresses the name before the @ is compared as well. It returns the kind of identifier
+// that matched ("username" or "email").
+func similarTo(password string, identifiers []string) (string, bool) {
+	password = strings.ToLower(password)
+	for _, identifier := range identifiers {
+		identifier = strings.ToLower(strings.TrimSpace(identifier))
+		kind := "username"
+		candidates := []string{identifier}
+		if at := strings.LastIndex(identifier, "@"); at > 0 {
+			kind = "email"
+			candidates = append(candidates, identifier[:at])
+		}
+		for _, candidate := range candidates {
+			if len(candidate) < minSimilarityLength {
+				continue
+			}
+			if strings.Contains(password, candidate) || strings.Contains(candidate, password) {
+				return kind, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,189 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"gonga/config"
+	logger "gonga/packages/Logger"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrQueueClosed is returned by Dispatch after the queue was shut down.
+var ErrQueueClosed = errors.New("queue: closed")
+
+// Job is a unit of background work. The context is cancelled when the queue shuts down.
+type Job func(ctx context.Context) error
+
+// task is a dispatched job waiting for a worker.
+type task struct {
+	name string
+	job  Job
+}
+
+// Queue runs dispatched jobs on a fixed number of worker goroutines and periodic jobs on a schedule.
+//
+// Jobs are kept in memory, jobs that did not run when the application stops are lost. Jobs that must
+// survive a restart should store their state in the database and be picked up by a scheduled job.
+type Queue struct {
+	sync    bool
+	workers int
+	tasks   chan task
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	closed  bool
+	started bool
+
+	scheduleCtx   context.Context
+	stopSchedules context.CancelFunc
+	schedules     sync.WaitGroup
+}
+
+// New returns a queue with the given number of workers. A sync queue runs every job immediately
+// in the goroutine that dispatches it, which is useful for tests and the console.
+func New(workers int, sync bool) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduleCtx, stopSchedules := context.WithCancel(ctx)
+	return &Queue{
+		sync:          sync,
+		workers:       workers,
+		tasks:         make(chan task, workers*64),
+		ctx:           ctx,
+		cancel:        cancel,
+		scheduleCtx:   scheduleCtx,
+		stopSchedules: stopSchedules,
+	}
+}
+
+// Start starts the workers. Jobs dispatched before Start wait until it is called.
+func (q *Queue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started || q.sync {
+		return
+	}
+	q.started = true
+
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for t := range q.tasks {
+				q.run(t)
+			}
+		}()
+	}
+}
+
+// Dispatch queues the job. The name is only used in logs.
+func (q *Queue) Dispatch(name string, job Job) error {
+	if q.sync {
+		q.run(task{name: name, job: job})
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	select {
+	case q.tasks <- task{name: name, job: job}:
+		return nil
+	default:
+		// The buffer is full, run the job without blocking the caller
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			q.run(task{name: name, job: job})
+		}()
+		return nil
+	}
+}
+
+// Every runs the job every interval until the queue shuts down. The first run is after one interval.
+func (q *Queue) Every(name string, interval time.Duration, job Job) {
+	q.schedules.Add(1)
+	go func() {
+		defer q.schedules.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-q.scheduleCtx.Done():
+				return
+			case <-ticker.C:
+				q.run(task{name: name, job: job})
+			}
+		}
+	}()
+}
+
+// Shutdown stops accepting jobs and the schedules, then waits until the queued jobs finished or ctx is done.
+// Running jobs see their context cancelled when ctx is done.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.tasks)
+	}
+	q.mu.Unlock()
+
+	q.stopSchedules()
+
+	done := make(chan struct{})
+	go func() {
+		q.schedules.Wait()
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) run(t task) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Default().Error("queue: job panicked", "job", t.name, "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	if err := t.job(q.ctx); err != nil {
+		logger.Default().Error("queue: job failed", "job", t.name, "error", err, "duration", time.Since(start).Round(time.Millisecond))
+	}
+}
+
+var (
+	defaultQueue     *Queue
+	defaultQueueOnce sync.Once
+)
+
+// Default returns the application queue configured by QUEUE_CONNECTION and QUEUE_WORKERS.
+//
+// Example usage:
+//
+//	queue.Default().Dispatch("send-welcome-email", func(ctx context.Context) error {
+//		return welcomeMail.Send()
+//	})
+func Default() *Queue {
+	defaultQueueOnce.Do(func() {
+		cfg := config.LoadQueueConfig()
+		defaultQueue = New(cfg.Workers, cfg.Connection == "sync")
+	})
+	return defaultQueue
+}
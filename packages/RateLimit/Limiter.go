@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"fmt"
+	"gonga/config"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows Requests requests per Window to each key. Requests are spread over the window, a client
+// that sent no requests for a whole window can send Requests requests at once.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// ParseLimit parses a limit of the form "requests,minutes", for example "60,1" for 60 requests a minute.
+func ParseLimit(spec string) (Limit, error) {
+	parts := strings.Split(spec, ",")
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("ratelimit: invalid limit %q, expected requests,minutes", spec)
+	}
+	requests, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || requests < 1 {
+		return Limit{}, fmt.Errorf("ratelimit: invalid number of requests in %q", spec)
+	}
+	minutes, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || minutes < 1 {
+		return Limit{}, fmt.Errorf("ratelimit: invalid number of minutes in %q", spec)
+	}
+	return Limit{Requests: requests, Window: time.Duration(minutes) * time.Minute}, nil
+}
+
+// Result is the state of a key's limit after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the key can send Limit requests again
+	Reset time.Duration
+	// RetryAfter is how long until the key can send the next request, 0 when the request was allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps the state of the limits. Take counts a request of the key against the limit.
+type Store interface {
+	Take(key string, limit Limit) (Result, error)
+}
+
+var (
+	defaultStore   Store
+	defaultStoreMu sync.Mutex
+)
+
+// Default returns the store of the application, an in-memory store sized by RATE_LIMIT_STORE_SIZE
+// unless SetDefault replaced it.
+func Default() Store {
+	defaultStoreMu.Lock()
+	defer defaultStoreMu.Unlock()
+	if defaultStore == nil {
+		defaultStore = NewMemoryStore(config.LoadRateLimitConfig().StoreSize)
+	}
+	return defaultStore
+}
+
+// SetDefault replaces the store of the application, for example with one shared by several servers.
+func SetDefault(store Store) {
+	defaultStoreMu.Lock()
+	defer defaultStoreMu.Unlock()
+	defaultStore = store
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps the limits in memory, for a single server.
+//
+// It remembers the most recently used keys up to its size and forgets the least recently used key
+// when it is full, which resets the limit of that key. Size it for the number of clients that are
+// active within a window.
+type MemoryStore struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	// order has the most recently used key at the front
+	order *list.List
+}
+
+// memoryEntry is the state of a key: the time at which the key has used up its whole limit, or earlier
+// when it has requests left. This is the generic cell rate algorithm, a token bucket in one timestamp.
+type memoryEntry struct {
+	key string
+	tat time.Time
+}
+
+// NewMemoryStore returns a store that remembers up to size keys.
+func NewMemoryStore(size int) *MemoryStore {
+	if size < 1 {
+		size = 1
+	}
+	return &MemoryStore{size: size, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// Take counts a request of the key against the limit.
+func (s *MemoryStore) Take(key string, limit Limit) (Result, error) {
+	now := time.Now()
+	interval := limit.Window / time.Duration(limit.Requests)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tat := now
+	element, ok := s.entries[key]
+	if ok {
+		if entryTat := element.Value.(*memoryEntry).tat; entryTat.After(now) {
+			tat = entryTat
+		}
+		s.order.MoveToFront(element)
+	}
+
+	result := Result{Limit: limit.Requests}
+	newTat := tat.Add(interval)
+	allowAt := newTat.Add(-limit.Window)
+	if now.Before(allowAt) {
+		result.Reset = tat.Sub(now)
+		result.RetryAfter = allowAt.Sub(now)
+		return result, nil
+	}
+
+	if ok {
+		element.Value.(*memoryEntry).tat = newTat
+	} else {
+		s.entries[key] = s.order.PushFront(&memoryEntry{key: key, tat: newTat})
+		if s.order.Len() > s.size {
+			oldest := s.order.Back()
+			s.order.Remove(oldest)
+			delete(s.entries, oldest.Value.(*memoryEntry).key)
+		}
+	}
+
+	result.Allowed = true
+	result.Remaining = int(now.Sub(allowAt) / interval)
+	result.Reset = newTat.Sub(now)
+	return result, nil
+}
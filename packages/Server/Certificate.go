@@ -0,0 +1,77 @@
+package server
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// certificateCheckInterval is how often the certificate files are checked for changes.
+const certificateCheckInterval = 10 * time.Second
+
+// CertificateReloader serves a TLS certificate from PEM files and loads it again when the files
+// change, so a renewed certificate is used without restarting the server.
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	modTime     time.Time
+	checkedAt   time.Time
+}
+
+// NewCertificateReloader loads the certificate and returns a reloader for it.
+func NewCertificateReloader(certFile, keyFile string) (*CertificateReloader, error) {
+	r := &CertificateReloader{certFile: certFile, keyFile: keyFile}
+	modTime, err := r.modified()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, it is used as tls.Config.GetCertificate. When the
+// changed files can't be loaded, for example while only one of them was replaced, the previous
+// certificate is served until they can.
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := time.Now(); now.Sub(r.checkedAt) >= certificateCheckInterval {
+		r.checkedAt = now
+		if modTime, err := r.modified(); err == nil && !modTime.Equal(r.modTime) {
+			_ = r.load(modTime)
+		}
+	}
+	return r.certificate, nil
+}
+
+func (r *CertificateReloader) load(modTime time.Time) error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.certificate = &certificate
+	r.modTime = modTime
+	return nil
+}
+
+// modified returns the latest modification time of the certificate and key files.
+func (r *CertificateReloader) modified() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"gonga/config"
+	"net"
+	"net/http"
+	"os"
+)
+
+// Server is an HTTP server configured by config.ServerConfig.
+type Server struct {
+	*http.Server
+	config *config.ServerConfig
+}
+
+// New returns a server for the handler with the timeouts of cfg. It serves HTTPS when cfg has a
+// certificate and fails when the certificate can't be loaded.
+func New(cfg *config.ServerConfig, handler http.Handler) (*Server, error) {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+			return nil, errors.New("server: TLS needs both TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+		reloader, err := NewCertificateReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+	}
+
+	return &Server{Server: srv, config: cfg}, nil
+}
+
+// Address describes where the server listens, for the startup message.
+func (s *Server) Address() string {
+	if s.config.Socket != "" {
+		return "unix:" + s.config.Socket
+	}
+	scheme := "http"
+	if s.TLSConfig != nil {
+		scheme = "https"
+	}
+	return scheme + "://localhost:" + s.config.Port
+}
+
+// Listen opens the Unix socket or the TCP port of the config. A socket file left behind by a
+// previous server is replaced.
+func (s *Server) Listen() (net.Listener, error) {
+	if s.config.Socket == "" {
+		return net.Listen("tcp", ":"+s.config.Port)
+	}
+
+	if info, err := os.Stat(s.config.Socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(s.config.Socket); err != nil {
+			return nil, err
+		}
+	}
+	listener, err := net.Listen("unix", s.config.Socket)
+	if err != nil {
+		return nil, err
+	}
+	// The reverse proxy usually runs as another user of the same group
+	if err := os.Chmod(s.config.Socket, 0660); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// Serve accepts connections on the listener until the server is shut down, then it returns
+// http.ErrServerClosed.
+func (s *Server) Serve(listener net.Listener) error {
+	if s.TLSConfig != nil {
+		return s.Server.ServeTLS(listener, "", "")
+	}
+	return s.Server.Serve(listener)
+}
+
+// Shutdown stops accepting connections and waits for the requests in progress until ctx is done,
+// then closes the remaining connections.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.Server.Shutdown(ctx)
+	if err != nil {
+		s.Server.Close()
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package packages
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteInfo describes a route of a MyRouter, see Routes.
+type RouteInfo struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Name       string   `json:"name,omitempty"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware"`
+}
+
+// routeInfo is what Handle records about a route, the path and name are read from the route itself.
+type routeInfo struct {
+	handler    string
+	middleware []string
+}
+
+// Routes returns the routes of the router and its groups in the order they are matched. The middleware
+// of a route is the middleware of its groups and of the route, in the order it runs; the middleware
+// added with Use runs before it for every route.
+func (r *MyRouter) Routes() []RouteInfo {
+	routes := []RouteInfo{}
+	_ = r.Router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		handler := route.GetHandler()
+		if handler == nil {
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+
+		info, ok := r.routes[route]
+		if !ok {
+			info = routeInfo{handler: funcName(handler)}
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{"ANY"}
+		}
+		for _, method := range methods {
+			routes = append(routes, RouteInfo{
+				Method:     method,
+				Path:       path,
+				Name:       route.GetName(),
+				Handler:    info.handler,
+				Middleware: append([]string{}, info.middleware...),
+			})
+		}
+		return nil
+	})
+	return routes
+}
+
+// closureSuffix matches the suffix the runtime adds to the names of closures and method values.
+var closureSuffix = regexp.MustCompile(`(\.func\d+|\.\d+)+$|-fm$`)
+
+// funcName returns the name of the function with its package, like "Controllers.PostController.Index".
+func funcName(fn interface{}) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func {
+		return value.Type().String()
+	}
+	f := runtime.FuncForPC(value.Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return closureSuffix.ReplaceAllString(name, "")
+}
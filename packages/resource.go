@@ -0,0 +1,173 @@
+package packages
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Indexer is a controller that lists the records of a resource, GET /{resource}.
+type Indexer interface {
+	Index(w http.ResponseWriter, r *http.Request)
+}
+
+// Shower is a controller that shows a record of a resource, GET /{resource}/{id}.
+type Shower interface {
+	Show(w http.ResponseWriter, r *http.Request)
+}
+
+// Creator is a controller that creates a record of a resource, POST /{resource}.
+type Creator interface {
+	Create(w http.ResponseWriter, r *http.Request)
+}
+
+// Updater is a controller that updates a record of a resource, PUT /{resource}/{id}.
+type Updater interface {
+	Update(w http.ResponseWriter, r *http.Request)
+}
+
+// Deleter is a controller that deletes a record of a resource, DELETE /{resource}/{id}.
+type Deleter interface {
+	Delete(w http.ResponseWriter, r *http.Request)
+}
+
+// The actions of a resource, used in ResourceOptions and as the last part of the route names.
+const (
+	ActionIndex  = "index"
+	ActionShow   = "show"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// ResourceOptions configures the routes registered by Resource.
+type ResourceOptions struct {
+	// Only registers just these actions, Except registers every action but these
+	Only   []string
+	Except []string
+
+	// Param is the route variable of the record, "id" by default
+	Param string
+
+	// Name is the name of the resource in the route names, like "posts" in "posts.index".
+	// It defaults to the path without variables, "/me/muted-words" is named "me.muted-words".
+	Name string
+
+	// Middleware is the middleware of each action, it runs after the middleware of the router's groups
+	Middleware map[string][]Middleware
+}
+
+// Resource registers the RESTful routes of the controller under the path, one for each of Index, Show,
+// Create, Update and Delete the controller implements:
+//
+//	GET    /posts       Index   posts.index
+//	POST   /posts       Create  posts.create
+//	GET    /posts/{id}  Show    posts.show
+//	PUT    /posts/{id}  Update  posts.update
+//	DELETE /posts/{id}  Delete  posts.delete
+//
+// It returns a group for the routes of a record, like /posts/{id}, to add routes beyond the RESTful
+// ones and nested resources. Nested resources need a Param that differs from the parent's.
+//
+// Example usage:
+//
+//	posts := router.Resource("/posts", PostController, packages.ResourceOptions{
+//		Except:     []string{packages.ActionUpdate},
+//		Middleware: map[string][]packages.Middleware{packages.ActionCreate: {middlewares.AuthMiddleware}},
+//	})
+//	posts.Put("/title", PostController.UpdateTitle, middlewares.AuthMiddleware)
+//	posts.Resource("/comments", CommentController, packages.ResourceOptions{
+//		Only:  []string{packages.ActionIndex, packages.ActionCreate},
+//		Param: "comment",
+//	})
+func (r *MyRouter) Resource(path string, controller interface{}, opts ResourceOptions) *MyRouter {
+	if opts.Param == "" {
+		opts.Param = "id"
+	}
+	if opts.Name == "" {
+		opts.Name = resourceName(path)
+	}
+	for _, action := range append(append([]string{}, opts.Only...), opts.Except...) {
+		if !isResourceAction(action) {
+			panic(fmt.Sprintf("packages: unknown resource action %q", action))
+		}
+	}
+	for action := range opts.Middleware {
+		if !isResourceAction(action) {
+			panic(fmt.Sprintf("packages: unknown resource action %q", action))
+		}
+	}
+	if strings.Contains(r.prefix+path, "{"+opts.Param+"}") || strings.Contains(r.prefix+path, "{"+opts.Param+":") {
+		panic(fmt.Sprintf("packages: the path %q already has the route variable %q, set ResourceOptions.Param", path, opts.Param))
+	}
+
+	member := path + "/{" + opts.Param + "}"
+	register := func(action, method, path string, handler http.HandlerFunc, methodName string) {
+		if !opts.includes(action) {
+			return
+		}
+		// The handler is a method value of the interface, name it after the controller's method instead
+		handlerName := methodName
+		if m, ok := reflect.TypeOf(controller).MethodByName(methodName); ok {
+			handlerName = funcName(m.Func.Interface())
+		}
+		r.handle(method, path, handler, handlerName, opts.Middleware[action]).Name(opts.Name + "." + action)
+	}
+
+	if c, ok := controller.(Indexer); ok {
+		register(ActionIndex, http.MethodGet, path, c.Index, "Index")
+	}
+	if c, ok := controller.(Creator); ok {
+		register(ActionCreate, http.MethodPost, path, c.Create, "Create")
+	}
+	if c, ok := controller.(Shower); ok {
+		register(ActionShow, http.MethodGet, member, c.Show, "Show")
+	}
+	if c, ok := controller.(Updater); ok {
+		register(ActionUpdate, http.MethodPut, member, c.Update, "Update")
+	}
+	if c, ok := controller.(Deleter); ok {
+		register(ActionDelete, http.MethodDelete, member, c.Delete, "Delete")
+	}
+
+	group := r.newGroup(member, nil)
+	group.namePrefix = r.namePrefix + opts.Name + "."
+	return group
+}
+
+// includes reports whether the action is registered according to Only and Except.
+func (opts ResourceOptions) includes(action string) bool {
+	if len(opts.Only) > 0 && !containsString(opts.Only, action) {
+		return false
+	}
+	return !containsString(opts.Except, action)
+}
+
+// resourceName turns the path of a resource into its name, for example "/me/muted-words" into "me.muted-words".
+func resourceName(path string) string {
+	parts := []string{}
+	for _, part := range strings.Split(path, "/") {
+		if part != "" && !strings.HasPrefix(part, "{") {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func isResourceAction(action string) bool {
+	switch action {
+	case ActionIndex, ActionShow, ActionCreate, ActionUpdate, ActionDelete:
+		return true
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,9 @@
 package packages
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/gorilla/mux"
 )
@@ -9,83 +11,222 @@ import (
 type HandlerFunc func(http.ResponseWriter, *http.Request)
 
 type Route interface {
-    Handle(method string, path string, handler HandlerFunc)
-    Get(path string, handler HandlerFunc)
-    Post(path string, handler HandlerFunc)
-    Put(path string, handler HandlerFunc)
-    Delete(path string, handler HandlerFunc)
+	Handle(method string, path string, handler HandlerFunc)
+	Get(path string, handler HandlerFunc)
+	Post(path string, handler HandlerFunc)
+	Put(path string, handler HandlerFunc)
+	Delete(path string, handler HandlerFunc)
 }
 
+// Middleware wraps a route handler, see applyMiddleware.
+type Middleware = func(http.HandlerFunc) http.HandlerFunc
+
 // MyRouter is a wrapper around Gorilla mux.Router which provides an easier and cleaner way to define routes and middleware.
+//
+// A MyRouter is either the root router or a group created by Group. Groups add their path prefix, middleware
+// and name prefix to every route registered on them, nested groups inherit those of their parents.
 type MyRouter struct {
-    *mux.Router
+	*mux.Router
+
+	// route is the route of the parent router that leads to this group, nil for the root router
+	route      *mux.Route
+	middleware []Middleware
+	namePrefix string
+
+	// prefix is the path prefix of the group, relative to its API version
+	prefix   string
+	versions *versionSet
+	version  *apiVersion
+
+	// routes describes the routes of the router and its groups for Routes
+	routes map[*mux.Route]routeInfo
+}
+
+// RouteHandle is a route registered on a MyRouter, it names the route for URL generation.
+type RouteHandle struct {
+	*mux.Route
+	router *MyRouter
+}
+
+// Name names the route, prefixed with the name prefix of its groups. Names must be unique.
+//
+// Example usage:
+//
+//	router.Post("/reset-password", NewPasswordController.Create).Name("password.update")
+func (h *RouteHandle) Name(name string) *RouteHandle {
+	// A route overridden in a later version already has its name, naming it again would break it
+	if name = h.router.namePrefix + name; h.Route.GetName() != name {
+		h.Route.Name(name)
+	}
+	return h
 }
 
 // NewRouter returns a new instance of MyRouter.
 func NewRouter() *MyRouter {
-    return &MyRouter{
-        Router: mux.NewRouter(),
-    }
+	return &MyRouter{
+		Router:   mux.NewRouter(),
+		versions: &versionSet{versions: map[string]*apiVersion{}},
+		routes:   map[*mux.Route]routeInfo{},
+	}
 }
 
-
 // Handle adds a new route with a specified HTTP method to the router.
-func (r *MyRouter) Handle(method string, path string, handler http.HandlerFunc) {
-    r.Router.HandleFunc(path, handler).Methods(method)
+// The middleware of the router's groups runs before the middleware of the route, then the handler.
+//
+// In an API version, registering a route again with the same method and path overrides the handler of the
+// first registration, see Versions.
+func (r *MyRouter) Handle(method string, path string, handler http.HandlerFunc, middleware ...Middleware) *RouteHandle {
+	return r.handle(method, path, handler, funcName(handler), middleware)
+}
 
+// handle adds the route, handlerName is the name of the handler listed by Routes.
+func (r *MyRouter) handle(method, path string, handler http.HandlerFunc, handlerName string, middleware []Middleware) *RouteHandle {
+	chain := append(append([]Middleware{}, r.middleware...), middleware...)
+	info := routeInfo{handler: handlerName, middleware: make([]string, 0, len(chain))}
+	for _, m := range chain {
+		info.middleware = append(info.middleware, funcName(m))
+	}
+
+	handler = applyMiddleware(handler, chain...)
+	if r.version != nil {
+		handler = r.version.middleware(handler)
+	}
+
+	var route *mux.Route
+	if r.version != nil {
+		key := method + " " + r.prefix + path
+		if existing, ok := r.version.routes[key]; ok {
+			route = existing.HandlerFunc(handler)
+		} else {
+			route = r.Router.HandleFunc(path, handler).Methods(method)
+			r.version.routes[key] = route
+		}
+	} else {
+		route = r.Router.HandleFunc(path, handler).Methods(method)
+	}
+	r.routes[route] = info
+	return &RouteHandle{Route: route, router: r}
+}
+
+// URL builds the path of the named route, pairs are the route variables as key/value pairs.
+// Groups share their names with the root router, so any of them can build the URL of any route.
+// Names without a version are looked up in the default API version.
+//
+// Example usage:
+//
+//	u, err := router.URL("exports.download", "token", token)
+func (r *MyRouter) URL(name string, pairs ...string) (*url.URL, error) {
+	route := r.Router.Get(name)
+	if route == nil && r.DefaultVersion() != "" {
+		route = r.Router.Get(r.DefaultVersion() + "." + name)
+	}
+	if route == nil {
+		return nil, fmt.Errorf("packages: no route named %q", name)
+	}
+	return route.URL(pairs...)
 }
 
 // Use adds a middleware to the router.
 func (r *MyRouter) Use(middleware func(http.Handler) http.Handler) *MyRouter {
-    r.Router.Use(middleware)
-    return r
+	r.Router.Use(middleware)
+	return r
+}
+
+// Group registers the routes added by fn under the path prefix. The middleware runs before the middleware
+// of each route, after the middleware of the enclosing groups. An empty prefix groups routes by middleware only.
+//
+// Example usage:
+//
+//	router.Group("/admin", func(admin *packages.MyRouter) {
+//		admin.Named("admin.")
+//		admin.Get("/users", UserController.Index, middlewares.PermissionMiddleware(policies.ViewUsers))
+//		admin.Group("/reports", func(reports *packages.MyRouter) {
+//			reports.Get("", ReportController.Index)
+//		}, middlewares.PermissionMiddleware(policies.ModerateContent))
+//	}, middlewares.AuthMiddleware)
+func (r *MyRouter) Group(prefix string, fn func(g *MyRouter), middleware ...Middleware) *MyRouter {
+	group := r.newGroup(prefix, middleware)
+	if fn != nil {
+		fn(group)
+	}
+	return group
+}
+
+func (r *MyRouter) newGroup(prefix string, middleware []Middleware) *MyRouter {
+	route := r.Router.NewRoute()
+	if prefix != "" {
+		route = route.PathPrefix(prefix)
+	}
+
+	return &MyRouter{
+		Router:     route.Subrouter(),
+		route:      route,
+		middleware: append(append([]Middleware{}, r.middleware...), middleware...),
+		namePrefix: r.namePrefix,
+		prefix:     r.prefix + prefix,
+		versions:   r.versions,
+		version:    r.version,
+		routes:     r.routes,
+	}
 }
 
-// Subrouter returns a new instance of MyRouter with the same configuration as the parent route.
+// Subrouter returns a group of routes under the path prefix that shares the middleware of the router.
 func (r *MyRouter) Subrouter(path string) *MyRouter {
-	// initialize a subrouter with a copy of the parent route's configuration
-	r.PathPrefix(path).Subrouter()
+	return r.Group(path, nil)
+}
+
+// Named sets the prefix of the names of the routes in the group, for example "admin.".
+// The prefix is added to the prefix of the enclosing groups.
+func (r *MyRouter) Named(prefix string) *MyRouter {
+	r.namePrefix += prefix
 	return r
 }
 
+// Domain restricts the routes of the group to requests for the host. The host may contain variables,
+// like "{account}.example.com", which are read with mux.Vars like path variables.
+// It panics when called on the root router, use a group instead.
+func (r *MyRouter) Domain(host string) *MyRouter {
+	if r.route == nil {
+		panic("packages: Domain must be called on a group")
+	}
+	r.route.Host(host)
+	return r
+}
 
 // Get adds a new route with the GET HTTP method and the specified path and handler function
-// 
-// Example usage: 
-//   router.Get("/users", getUsersHandler)
-func (r *MyRouter) Get(path string, handler http.HandlerFunc, middleware ...func(http.HandlerFunc) http.HandlerFunc) *MyRouter {
-    r.Handle("GET", path, applyMiddleware(handler, middleware...))
-    return r
+//
+// Example usage:
+//
+//	router.Get("/users", getUsersHandler)
+func (r *MyRouter) Get(path string, handler http.HandlerFunc, middleware ...Middleware) *RouteHandle {
+	return r.Handle("GET", path, handler, middleware...)
 }
 
-
 // Post adds a new route with the POST HTTP method and the specified path and handler function
 //
-// Example usage: 
-//   router.Post("/users", createUserHandler)
-func (r *MyRouter) Post(path string, handler http.HandlerFunc, middleware ...func(http.HandlerFunc) http.HandlerFunc) *MyRouter{
-    r.Handle("POST", path, applyMiddleware(handler, middleware...))
-    return r
+// Example usage:
+//
+//	router.Post("/users", createUserHandler)
+func (r *MyRouter) Post(path string, handler http.HandlerFunc, middleware ...Middleware) *RouteHandle {
+	return r.Handle("POST", path, handler, middleware...)
 }
 
-
 // Put adds a new route with the PUT HTTP method and the specified path and handler function
 //
 // Example usage:
-//   router.Put("/users/{id}", updateUserHandler)
-func (r *MyRouter) Put(path string, handler http.HandlerFunc, middleware ...func(http.HandlerFunc) http.HandlerFunc) *MyRouter{
-    r.Handle("PUT", path, applyMiddleware(handler, middleware...))
-    return r
+//
+//	router.Put("/users/{id}", updateUserHandler)
+func (r *MyRouter) Put(path string, handler http.HandlerFunc, middleware ...Middleware) *RouteHandle {
+	return r.Handle("PUT", path, handler, middleware...)
 }
 
-
 // Delete adds a new route with the DELETE HTTP method and the specified path and handler function
 //
-// Example usage: 
-//   router.Delete("/users/{id}", deleteUserHandler)
-func (r *MyRouter) Delete(path string, handler http.HandlerFunc, middleware ...func(http.HandlerFunc) http.HandlerFunc) *MyRouter{
-    r.Handle("DELETE", path, applyMiddleware(handler, middleware...))
-    return r
+// Example usage:
+//
+//	router.Delete("/users/{id}", deleteUserHandler)
+func (r *MyRouter) Delete(path string, handler http.HandlerFunc, middleware ...Middleware) *RouteHandle {
+	return r.Handle("DELETE", path, handler, middleware...)
 }
 
 // applyMiddleware applies a chain of middleware functions to an HTTP handler function
@@ -100,14 +241,15 @@ func (r *MyRouter) Delete(path string, handler http.HandlerFunc, middleware ...f
 //   - An HTTP handler function with the middleware applied.
 //
 // Example usage:
-//   router.Get("/users", applyMiddleware(listUsersHandler, authMiddleware, loggingMiddleware))
-func applyMiddleware(h http.HandlerFunc, middleware ...func(http.HandlerFunc) http.HandlerFunc) http.HandlerFunc {
-    if len(middleware) == 0 {
-        return h
-    }
-    wrapped := h
-    for i := len(middleware) - 1; i >= 0; i-- {
-        wrapped = middleware[i](wrapped)
-    }
-    return wrapped
+//
+//	router.Get("/users", applyMiddleware(listUsersHandler, authMiddleware, loggingMiddleware))
+func applyMiddleware(h http.HandlerFunc, middleware ...Middleware) http.HandlerFunc {
+	if len(middleware) == 0 {
+		return h
+	}
+	wrapped := h
+	for i := len(middleware) - 1; i >= 0; i-- {
+		wrapped = middleware[i](wrapped)
+	}
+	return wrapped
 }
@@ -0,0 +1,60 @@
+package packages
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testPosts struct {
+	body string
+}
+
+func (c testPosts) Index(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(c.body + " index"))
+}
+
+func (c testPosts) Show(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(c.body + " show"))
+}
+
+func serve(router http.Handler, method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder
+}
+
+func TestVersionOverridesNamedResourceRoutes(t *testing.T) {
+	router := NewRouter()
+	router.Versions([]string{"v1", "v2"}, func(v *MyRouter) {
+		v.Resource("/posts", testPosts{body: "shared"}, ResourceOptions{})
+	})
+	router.Version("v2", func(v2 *MyRouter) {
+		v2.Resource("/posts", testPosts{body: "v2"}, ResourceOptions{})
+	})
+
+	tests := []struct {
+		path string
+		body string
+	}{
+		{"/v1/posts", "shared index"},
+		{"/v1/posts/1", "shared show"},
+		{"/v2/posts", "v2 index"},
+		{"/v2/posts/1", "v2 show"},
+	}
+	for _, test := range tests {
+		res := serve(router, http.MethodGet, test.path)
+		if res.Code != http.StatusOK || res.Body.String() != test.body {
+			t.Errorf("GET %s = %d %q, want 200 %q", test.path, res.Code, res.Body.String(), test.body)
+		}
+	}
+
+	for name, path := range map[string]string{"v1.posts.index": "/v1/posts", "v2.posts.show": "/v2/posts/1"} {
+		u, err := router.URL(name, "id", "1")
+		if err != nil {
+			t.Errorf("URL(%q): %v", name, err)
+		} else if u.Path != path {
+			t.Errorf("URL(%q) = %s, want %s", name, u.Path, path)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package packages
+
+import (
+	"gonga/config"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var (
+	defaultRouter     *MyRouter
+	defaultRouterOnce sync.Once
+)
+
+// Default returns the application router. The routes registered on it are the ones URL can link to.
+func Default() *MyRouter {
+	defaultRouterOnce.Do(func() {
+		defaultRouter = NewRouter()
+	})
+	return defaultRouter
+}
+
+// URL builds the absolute URL of the named route of the application router, for links in emails and
+// API responses. The URL starts with the APP_URL, pairs are the route variables as key/value pairs
+// and query, when not nil, is added as the query string.
+//
+// Example usage:
+//
+//	link, err := packages.URL("exports.download", nil, "token", token)
+func URL(name string, query url.Values, pairs ...string) (string, error) {
+	u, err := Default().URL(name, pairs...)
+	if err != nil {
+		return "", err
+	}
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+	return AbsoluteURL(u.String()), nil
+}
+
+// FrontendURL builds the URL of a page of the frontend, which starts with the FRONTEND_URL. Links in
+// emails that the user opens in a browser point to the frontend, the API routes behind the pages don't
+// answer GET requests.
+//
+// Example usage:
+//
+//	link := packages.FrontendURL("/reset-password", url.Values{"token": {token}})
+func FrontendURL(path string, query url.Values) string {
+	base := strings.TrimRight(config.LoadAppConfig().FrontendURL, "/")
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return base + path
+}
+
+// AbsoluteURL prefixes the path with the APP_URL.
+func AbsoluteURL(path string) string {
+	base := strings.TrimRight(config.LoadAppConfig().URL, "/")
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+	return base + path
+}
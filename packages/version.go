@@ -0,0 +1,177 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"gonga/utils"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// acceptVersion matches the media type clients send in the Accept header to ask for an API version.
+var acceptVersion = regexp.MustCompile(`application/vnd\.gonga\.(v[0-9]+)\+json`)
+
+// versionKey is the request context key of the API version of the route.
+type versionKey struct{}
+
+// apiVersion is a version of the API registered with MyRouter.Version.
+type apiVersion struct {
+	name       string
+	deprecated time.Time
+	sunset     time.Time
+
+	// routes are the routes of the version by method and path, for per-version overrides
+	routes map[string]*mux.Route
+}
+
+// versionSet holds the API versions of a router and its groups, in the order they were registered.
+type versionSet struct {
+	versions map[string]*apiVersion
+	order    []string
+}
+
+// Version registers the routes added by fn under the version's path prefix, for example "/v1" for "v1".
+// Requests without a version prefix are served by the version their Accept header asks for, like
+// "application/vnd.gonga.v2+json", or by the first registered version, the default version.
+// Calling Version again for the same version adds routes to it, registering a route twice overrides it.
+// Route names in a version are prefixed with the version, for example "v1.password.update".
+//
+// Version must be called on the root router, it panics when called on a group.
+//
+// Example usage:
+//
+//	router.Version("v1", func(v1 *packages.MyRouter) {
+//		v1.Get("/posts", PostController.Index)
+//	})
+func (r *MyRouter) Version(name string, fn func(v *MyRouter), middleware ...Middleware) *MyRouter {
+	if r.route != nil {
+		panic("packages: Version must be called on the root router")
+	}
+
+	version, ok := r.versions.versions[name]
+	if !ok {
+		version = &apiVersion{name: name, routes: map[string]*mux.Route{}}
+		r.versions.versions[name] = version
+		r.versions.order = append(r.versions.order, name)
+	}
+
+	group := r.newGroup("/"+name, middleware)
+	group.prefix = ""
+	group.version = version
+	group.namePrefix = r.namePrefix + name + "."
+	if fn != nil {
+		fn(group)
+	}
+	return group
+}
+
+// Versions registers the routes added by fn in every version, so one handler serves several versions.
+// A version changes a route by registering it again in a later call to Version.
+//
+// Example usage:
+//
+//	router.Versions([]string{"v1", "v2"}, func(v *packages.MyRouter) {
+//		v.Get("/posts", PostController.Index)
+//	})
+//	router.Version("v2", func(v2 *packages.MyRouter) {
+//		v2.Get("/posts", PostController.IndexV2)
+//	})
+func (r *MyRouter) Versions(names []string, fn func(v *MyRouter), middleware ...Middleware) {
+	for _, name := range names {
+		r.Version(name, fn, middleware...)
+	}
+}
+
+// Deprecate retires the version. Its responses carry the Deprecation header with the date the version
+// was deprecated and, when sunset is not zero, the Sunset header with the date it stops working.
+// It panics when the version is not registered.
+func (r *MyRouter) Deprecate(name string, deprecated, sunset time.Time) *MyRouter {
+	version, ok := r.versions.versions[name]
+	if !ok {
+		panic(fmt.Sprintf("packages: API version %q is not registered", name))
+	}
+	version.deprecated = deprecated
+	version.sunset = sunset
+	return r
+}
+
+// DefaultVersion returns the version that serves requests without a version, "" when the router has no versions.
+func (r *MyRouter) DefaultVersion() string {
+	if len(r.versions.order) == 0 {
+		return ""
+	}
+	return r.versions.order[0]
+}
+
+// APIVersions returns the registered API versions, the default version first.
+func (r *MyRouter) APIVersions() []string {
+	return append([]string{}, r.versions.order...)
+}
+
+// RequestVersion returns the API version of the route serving the request, "" when the route is not versioned.
+// Handlers shared by several versions use it to tell the versions apart.
+func RequestVersion(r *http.Request) string {
+	version, _ := r.Context().Value(versionKey{}).(string)
+	return version
+}
+
+// ServeHTTP dispatches the request to the matching route. A request without a version prefix that
+// matches no unversioned route is served by the version negotiated from its Accept header.
+func (r *MyRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.route == nil && len(r.versions.order) > 0 && !r.versions.prefixed(req.URL.Path) {
+		var match mux.RouteMatch
+		if !r.Router.Match(req, &match) {
+			version, ok := r.versions.negotiate(req.Header.Get("Accept"))
+			if !ok {
+				utils.JSONResponse(w, http.StatusNotAcceptable, utils.APIResponse{
+					Type:    "error",
+					Message: "the requested API version is not supported",
+				})
+				return
+			}
+			w.Header().Add("Vary", "Accept")
+			req.URL.Path = "/" + version + req.URL.Path
+			if req.URL.RawPath != "" {
+				req.URL.RawPath = "/" + version + req.URL.RawPath
+			}
+		}
+	}
+	r.Router.ServeHTTP(w, req)
+}
+
+// prefixed reports whether the path starts with the prefix of a version.
+func (s *versionSet) prefixed(path string) bool {
+	for _, name := range s.order {
+		if path == "/"+name || strings.HasPrefix(path, "/"+name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiate picks the version the Accept header asks for, or the default version when it asks for none.
+func (s *versionSet) negotiate(accept string) (string, bool) {
+	match := acceptVersion.FindStringSubmatch(accept)
+	if match == nil {
+		return s.order[0], true
+	}
+	_, ok := s.versions[match[1]]
+	return match[1], ok
+}
+
+// middleware adds the version to the request context and sends the deprecation headers of retired versions.
+func (v *apiVersion) middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !v.deprecated.IsZero() {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", v.deprecated.Unix()))
+		}
+		if !v.sunset.IsZero() {
+			w.Header().Set("Sunset", v.sunset.UTC().Format(http.TimeFormat))
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), versionKey{}, v.name)))
+	}
+}
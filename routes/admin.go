@@ -0,0 +1,62 @@
+package routes
+
+import (
+	admin "gonga/app/Http/Controllers/Admin"
+	middlewares "gonga/app/Http/Middlewares"
+	policies "gonga/app/Policies"
+	"gonga/packages"
+
+	"gorm.io/gorm"
+)
+
+// RegisterAdminRoutes registers the /admin routes. Every route requires an authenticated user
+// with the listed permission, the controllers additionally check the policy of the record.
+func RegisterAdminRoutes(router *packages.MyRouter, db *gorm.DB) {
+	// Initialize the required controllers
+	UserController := admin.UserController{DB: db}
+	PostController := admin.PostController{DB: db}
+	CommentController := admin.CommentController{DB: db}
+	ReportController := admin.ReportController{DB: db}
+
+	// Every admin route passes the AuthMiddleware first and then the permission check
+	router.Group("/admin", func(g *packages.MyRouter) {
+		g.Named("admin.")
+
+		// User management
+		users := g.Resource("/users", UserController, packages.ResourceOptions{
+			Middleware: map[string][]packages.Middleware{
+				packages.ActionIndex:  {can(policies.ViewUsers)},
+				packages.ActionDelete: {can(policies.ManageUsers)},
+			},
+		})
+		users.Group("", func(g *packages.MyRouter) {
+			g.Post("/suspend", UserController.Suspend)
+			g.Delete("/suspend", UserController.Unsuspend)
+			g.Put("/state", UserController.UpdateState)
+		}, can(policies.SuspendUsers))
+		users.Group("", func(g *packages.MyRouter) {
+			g.Put("/role", UserController.UpdateRole)
+			g.Put("/badge", UserController.GrantBadge)
+			g.Delete("/badge", UserController.RevokeBadge)
+			g.Get("/verifications", UserController.Verifications)
+		}, can(policies.ManageUsers))
+
+		// Content moderation
+		g.Group("", func(g *packages.MyRouter) {
+			g.Resource("/posts", PostController, packages.ResourceOptions{})
+			g.Resource("/comments", CommentController, packages.ResourceOptions{})
+
+			// Moderation queue
+			reports := g.Resource("/reports", ReportController, packages.ResourceOptions{})
+			reports.Post("/claim", ReportController.Claim)
+			reports.Delete("/claim", ReportController.Release)
+			reports.Post("/resolve", ReportController.Resolve)
+			g.Get("/moderation-log", ReportController.AuditLog)
+		}, can(policies.ModerateContent))
+	}, middlewares.AuthMiddleware)
+}
+
+// can is the permission check of an admin route.
+func can(permission policies.Permission) packages.Middleware {
+	return middlewares.PermissionMiddleware(permission)
+}
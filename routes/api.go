@@ -12,6 +12,7 @@ import (
 func RegisterApiRoutes(router *packages.MyRouter, db *gorm.DB) {
 	// Initialize the required controllers
 	UserController := controllers.UserController{DB: db}
+	AccountController := controllers.AccountController{DB: db}
 	SearchController := controllers.SearchController{DB: db}
 	PostController := controllers.PostController{DB: db}
 	NotificationController := controllers.NotificationController{DB: db}
@@ -19,47 +20,108 @@ func RegisterApiRoutes(router *packages.MyRouter, db *gorm.DB) {
 	MediaController := controllers.MediaController{DB: db}
 	CommentController := controllers.CommentController{DB: db}
 	LikeController := controllers.LikeController{DB: db}
+	BlockController := controllers.BlockController{DB: db}
+	MuteController := controllers.MuteController{DB: db}
+	ReportController := controllers.ReportController{DB: db}
+	MutedWordController := controllers.MutedWordController{DB: db}
+	AppealController := controllers.AppealController{DB: db}
 
-	router.Post("/upload", MediaController.Upload, middlewares.AuthMiddleware)
-	// User API endpoint handlers
+	// Guests can read, logged in callers get responses tailored to them, for example without the
+	// content of the users they blocked
+	optional := []packages.Middleware{middlewares.OptionalAuthMiddleware}
+	authenticated := []packages.Middleware{middlewares.AuthMiddleware}
+	// Clients can retry creating content with an Idempotency-Key without creating it twice
+	idempotent := []packages.Middleware{middlewares.AuthMiddleware, middlewares.IdempotencyMiddleware}
+
+	// Public routes
 	router.Get("/users", UserController.Index)
-	router.Get("/users/{username}", UserController.Show)
-	router.Put("/users/{username}", UserController.Update, middlewares.AuthMiddleware)
-	router.Delete("/users/{id}", UserController.Delete, middlewares.AuthMiddleware)
+	router.Post("/me/email/confirm", AccountController.ConfirmEmail).Name("email.confirm")
+	router.Get("/exports/{token}", AccountController.DownloadExport).Name("exports.download")
+	router.Get("/users/{username}", UserController.Show, optional...)
+	router.Get("/search", SearchController.Index, optional...)
+	// Suspended users can't log in, they appeal with the token they got from the login instead.
+	// Resources outside the authenticated group list their actions with Only, an action the controller
+	// gains later isn't registered without auth.
+	router.Resource("/appeals", AppealController, packages.ResourceOptions{
+		Only:       []string{packages.ActionCreate},
+		Middleware: map[string][]packages.Middleware{packages.ActionCreate: optional},
+	})
 
 	// Post API endpoint handlers
-	router.Get("/posts", PostController.Index)
-	router.Post("/posts", PostController.Create, middlewares.AuthMiddleware) //, middlewares.AuthMiddleware
-	router.Get("/posts/{id}", PostController.Show)
-	// router.Put("/posts/{id}", PostController.Update, middlewares.AuthMiddleware)
-	router.Put("/posts/{id}/title", PostController.UpdateTitle, middlewares.AuthMiddleware)
-	router.Put("/posts/{id}/body", PostController.UpdateBody, middlewares.AuthMiddleware)
-	router.Put("/posts/{id}/medias", PostController.UpdateMedia, middlewares.AuthMiddleware)
-	router.Put("/posts/{id}/hashtags", PostController.UpdateHashtag, middlewares.AuthMiddleware)
-	router.Put("/posts/{id}/settings", PostController.UpdatePostSettings, middlewares.AuthMiddleware)
-	router.Delete("/posts/{id}", PostController.Delete, middlewares.AuthMiddleware)
-
-	// Comment API endpoint handlers
-	router.Get("/posts/{id}/comments", CommentController.Index)
-	router.Post("/posts/{id}/comments", CommentController.Create, middlewares.AuthMiddleware)
-	router.Get("/comments/{id}", CommentController.Show)
-	router.Put("/comments/{id}", CommentController.Update, middlewares.AuthMiddleware)
-	router.Delete("/comments/{id}", CommentController.Delete, middlewares.AuthMiddleware)
-
-	//like API endpoint handlers
-	router.Post("/likes", LikeController.Create, middlewares.AuthMiddleware)
-	router.Delete("/likes/{id}", LikeController.Delete, middlewares.AuthMiddleware)
-
-	// Follow API endpoint handlers
-	router.Post("/users/follow", FollowController.Create, middlewares.AuthMiddleware)
-
-	// Notification API endpoint handlers
-	router.Get("/notifications", NotificationController.Index, middlewares.AuthMiddleware)
-	router.Post("/notifications/read_all", NotificationController.ReadAll, middlewares.AuthMiddleware)
-	router.Post("/notifications/{id}/read", NotificationController.Update, middlewares.AuthMiddleware)
-
-	// Search API endpoint handlers
-	router.Get("/search", SearchController.Index)
+	posts := router.Resource("/posts", PostController, packages.ResourceOptions{
+		Only: []string{packages.ActionIndex, packages.ActionShow, packages.ActionCreate, packages.ActionDelete},
+		Middleware: map[string][]packages.Middleware{
+			packages.ActionIndex:  optional,
+			packages.ActionShow:   optional,
+			packages.ActionCreate: idempotent,
+			packages.ActionDelete: authenticated,
+		},
+	})
+	posts.Group("", func(g *packages.MyRouter) {
+		g.Put("/title", PostController.UpdateTitle)
+		g.Put("/body", PostController.UpdateBody)
+		g.Put("/medias", PostController.UpdateMedia)
+		g.Put("/hashtags", PostController.UpdateHashtag)
+		g.Put("/settings", PostController.UpdatePostSettings)
+	}, middlewares.AuthMiddleware)
+
+	// Comment API endpoint handlers, a post's comments are listed and created under the post
+	posts.Resource("/comments", CommentController, packages.ResourceOptions{
+		Only:  []string{packages.ActionIndex, packages.ActionCreate},
+		Param: "comment",
+		Middleware: map[string][]packages.Middleware{
+			packages.ActionIndex:  optional,
+			packages.ActionCreate: idempotent,
+		},
+	})
+	router.Resource("/comments", CommentController, packages.ResourceOptions{
+		Only: []string{packages.ActionShow, packages.ActionUpdate, packages.ActionDelete},
+		Middleware: map[string][]packages.Middleware{
+			packages.ActionShow:   optional,
+			packages.ActionUpdate: authenticated,
+			packages.ActionDelete: authenticated,
+		},
+	})
+
+	// Routes for authenticated users
+	router.Group("", func(g *packages.MyRouter) {
+		g.Post("/upload", MediaController.Upload)
+
+		// User API endpoint handlers
+		g.Put("/users/{username}", UserController.Update)
+		g.Delete("/users/{id}", UserController.Delete)
+
+		// Account API endpoint handlers
+		g.Put("/me/password", AccountController.UpdatePassword)
+		g.Put("/me/email", AccountController.UpdateEmail)
+		g.Post("/me/export", AccountController.RequestExport)
+
+		//like API endpoint handlers
+		g.Resource("/likes", LikeController, packages.ResourceOptions{})
+
+		// Block and mute API endpoint handlers
+		g.Get("/me/blocks", BlockController.Index)
+		g.Post("/users/{username}/block", BlockController.Create)
+		g.Delete("/users/{username}/block", BlockController.Delete)
+		g.Get("/me/mutes", MuteController.Index)
+		g.Post("/users/{username}/mute", MuteController.Create)
+		g.Delete("/users/{username}/mute", MuteController.Delete)
+		g.Resource("/me/muted-words", MutedWordController, packages.ResourceOptions{})
+
+		// Report API endpoint handlers
+		g.Resource("/reports", ReportController, packages.ResourceOptions{})
+
+		// Follow API endpoint handlers
+		g.Post("/users/follow", FollowController.Create)
+
+		// Notification API endpoint handlers
+		g.Get("/notifications", NotificationController.Index)
+		g.Post("/notifications/read_all", NotificationController.ReadAll)
+		g.Post("/notifications/{id}/read", NotificationController.Update)
+	}, middlewares.AuthMiddleware)
+
+	// Admin API endpoint handlers
+	RegisterAdminRoutes(router, db)
 
 	// ******************************
 	// *    ALERT: DO NOT EDIT!     *
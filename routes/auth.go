@@ -3,7 +3,9 @@ package routes
 import (
 	auth "gonga/app/Http/Controllers/Auth"
 	middlewares "gonga/app/Http/Middlewares"
+	"gonga/config"
 	"gonga/packages"
+	oauth "gonga/packages/OAuth"
 
 	"gorm.io/gorm"
 )
@@ -15,18 +17,37 @@ func RegisterAuthRoutes(router *packages.MyRouter, db *gorm.DB) {
 	RegisterController := auth.RegisterController{DB: db}
 	NewPasswordController := auth.NewPasswordController{DB: db}
 	PasswordResetLinkController := auth.PasswordResetLinkController{DB: db}
+	TwoFactorController := auth.TwoFactorController{DB: db}
+	SocialLoginController := auth.SocialLoginController{DB: db, Providers: oauth.NewManager(config.LoadAuthConfig())}
+	KeySetController := auth.KeySetController{}
+
+	// Token verification keys for other services
+	router.Get("/.well-known/jwks.json", KeySetController.Index)
 
 	// Login API endpoint handlers
 	router.Post("/login", LoginController.Create)
+	router.Post("/login/2fa", LoginController.TwoFactor)
+
+	// Social login API endpoint handlers
+	router.Get("/auth/{provider}/redirect", SocialLoginController.Redirect)
+	router.Get("/auth/{provider}/callback", SocialLoginController.Callback)
 
 	// Logout API endpoint handlers
 	router.Post("/logout", LoginController.Delete, middlewares.AuthMiddleware)
 
 	// Register API endpoint handlers
-	router.Post("/register", RegisterController.Create)
+	router.Post("/register", RegisterController.Create, middlewares.Throttle("throttle:6,1,ip"))
 
 	// Password reset API endpoint handlers
-	router.Post("/forgot-password", PasswordResetLinkController.Create).Name("password.email")
+	router.Post("/forgot-password", PasswordResetLinkController.Create, middlewares.Throttle("throttle:6,1,ip")).Name("password.email")
 	router.Post("/reset-password", NewPasswordController.Create).Name("password.update")
 
+	// Two-factor authentication API endpoint handlers
+	router.Group("/user", func(g *packages.MyRouter) {
+		g.Post("/two-factor-authentication", TwoFactorController.Create)
+		g.Post("/confirmed-two-factor-authentication", TwoFactorController.Confirm)
+		g.Post("/two-factor-recovery-codes", TwoFactorController.RecoveryCodes)
+		g.Delete("/two-factor-authentication", TwoFactorController.Delete)
+	}, middlewares.AuthMiddleware)
+
 }
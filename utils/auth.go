@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
@@ -12,128 +11,12 @@ import (
 	"log"
 	"net/http"
 	"strings"
-	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/gddo/httputil/header"
-	"github.com/google/uuid"
 	"github.com/gorilla/schema"
 	"gorm.io/gorm"
 )
 
-// IsAuthenticate checks if the user is authenticated by verifying the JWT token present in the request header.
-//
-// It expects the JWT token to be present in the "token" field of the request header. If it's not found, or if the token
-// is invalid or expired, this function returns false. Otherwise, it returns true indicating that the user is authenticated.
-//
-// Example usage:
-//
-//	isAuthenticated := IsAuthenticate(r)
-//	if isAuthenticated {
-//	    // perform authenticated operations
-//	} else {
-//	    // redirect to login page or send unauthorized response
-//	}
-//
-// Parameters:
-//
-//	r (*http.Request): The HTTP request object containing the token in its header.
-//
-// Returns:
-//
-//	bool: A boolean value indicating whether the user is authenticated or not.
-func IsAuthenticate(r *http.Request) (bool, interface{}) {
-	// Check if user is authenticated
-	tokenString := r.Header.Get("Authorization")
-	if tokenString == "" {
-		return false, nil
-	}
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Provide a signing key
-		return []byte(Env("APP_KEY", "my-secret-key")), nil
-	})
-	if err != nil {
-		return false, nil
-	}
-	if !token.Valid {
-		return false, nil
-	}
-
-	// Extract the user ID from the token's claims
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return false, nil
-	}
-
-	userID, exists := claims["userID"]
-	if !exists {
-		return false, nil
-	}
-
-	return true, userID
-}
-
-func ExtractUserIDFromToken(tokenString string) (int, error) {
-	// Parse the JWT token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Add your logic to provide the token's signing key here
-		// For example, you can use a shared secret key or fetch the key from a database
-		// Make sure to return the correct signing key based on the token's signing method
-		// In this example, we assume the token is signed using an HMAC algorithm
-		return []byte(Env("APP_KEY", "my-secret-key")), nil
-	})
-
-	if err != nil {
-		return 0, err
-	}
-
-	// Verify the token's validity
-	if !token.Valid {
-		return 0, errors.New("invalid token")
-	}
-
-	// Extract the user ID from the token's claims
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, errors.New("invalid token claims")
-	}
-
-	userID, ok := claims["userID"].(int)
-	if !ok {
-		return 0, errors.New("user ID not found in token claims")
-	}
-
-	return userID, nil
-}
-
-func GetUserIDFromContext(ctx context.Context) (interface{}, error) {
-	const (
-		userIDKey ContextKey = "userID"
-	)
-	userID := ctx.Value(userIDKey)
-
-	if userID != nil {
-		switch v := userID.(type) {
-		case uint:
-			return v, nil
-		case float64:
-			return v, nil
-		case string:
-			// Convert the string to the appropriate type (e.g., UUID)
-			// Handle different type conversions as needed
-			userUUID, err := uuid.Parse(v)
-			if err != nil {
-				return nil, err
-			}
-			return userUUID, nil
-		default:
-			return nil, fmt.Errorf("unsupported user ID type: %T", userID)
-		}
-	}
-	return nil, errors.New("user ID not found in context")
-}
-
 // Authenticate authenticates a user by checking the provided username and password against the database.
 //
 // It fetches the user with the given username from the database using the provided *gorm.DB object. If the user is found,
@@ -202,49 +85,6 @@ func UserExistsWithEmail(db *gorm.DB, email string) bool {
 	return result.Error == nil && result.RowsAffected > 0
 }
 
-// GenerateToken generates a new JWT token for the given user ID.
-//
-// This function generates a new JWT token with the given user ID as the "userID" claim and a default expiration time of 24 hours.
-// The token is signed using the secret key retrieved from the "APP_KEY" environment variable. If the environment variable is not set,
-// a default secret key of "my-secret-key" is used. The function returns the generated token as a string and an error, if any.
-//
-// Example usage:
-//
-//	userID := 1234
-//	token, err := GenerateToken(userID)
-//	if err != nil {
-//	    log.Fatal(err)
-//	}
-//	fmt.Println("Token:", token)
-//
-// Parameters:
-//
-//	userID (int): The ID of the user for whom the token is being generated.
-//
-// Returns:
-//
-//	string: The generated JWT token.
-//	error: An error, if any, that occurred during the token generation process.
-func GenerateToken(userID int) (string, error) {
-	// Create the token
-	token := jwt.New(jwt.SigningMethodHS256)
-
-	// Set the claims
-	claims := token.Claims.(jwt.MapClaims)
-	claims["userID"] = userID
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix() // Token expires in 24 hours
-
-	// Get the secret key from an environment variable
-	secretKey := []byte(Env("APP_KEY", "my-secret-key"))
-
-	// Generate the signed token string
-	tokenString, err := token.SignedString(secretKey)
-	if err != nil {
-		return "", err
-	}
-	return tokenString, nil // Return a jwt token
-}
-
 // DecodeRequestBody decodes the form data in the http request body and maps it to a struct
 //
 // This function takes an http request object and a struct to be decoded into, and returns an error
@@ -279,6 +119,9 @@ func DecodeRequestBody(r *http.Request, v interface{}) error {
 	return nil
 }
 
+// MaxJSONBodySize is the largest request body DecodeJSONBody reads, 1MB.
+const MaxJSONBodySize = 1048576
+
 // DecodeJSONBody decodes the JSON request body into the provided destination object.
 // It performs validation and error handling for the decoding process.
 //
@@ -330,7 +173,7 @@ func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) err
 		}
 	}
 
-	r.Body = http.MaxBytesReader(w, r.Body, 1048576)
+	r.Body = http.MaxBytesReader(w, r.Body, MaxJSONBodySize)
 
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
@@ -220,3 +222,44 @@ func GetParam(r *http.Request, paramName string) (string, error) {
 	}
 	return val, nil
 }
+
+// ClientIP returns the IP address of the client that sent the request.
+//
+// The X-Forwarded-For header is only honoured when TRUST_PROXY_HEADERS is true, because any
+// client can set it. Every proxy appends the address it received the request from, so only the
+// entries added by our own proxies can be trusted: with TRUSTED_PROXY_COUNT proxies in front of
+// the app, the client is that many entries from the right. Entries further left are ignored.
+//
+// Example usage:
+//
+//	ip := ClientIP(r)
+//
+// Parameters:
+//   - r (*http.Request): The incoming HTTP request.
+//
+// Returns:
+//   - string: The client IP address.
+func ClientIP(r *http.Request) string {
+	if strings.EqualFold(Env("TRUST_PROXY_HEADERS", "false"), "true") {
+		var hops []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			for _, hop := range strings.Split(header, ",") {
+				if hop = strings.TrimSpace(hop); hop != "" {
+					hops = append(hops, hop)
+				}
+			}
+		}
+		if proxies := EnvInt("TRUSTED_PROXY_COUNT", 1); proxies > 0 && len(hops) > 0 {
+			if proxies > len(hops) {
+				proxies = len(hops)
+			}
+			return hops[len(hops)-proxies]
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -4,6 +4,8 @@ import (
 	"log"
 	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -84,6 +86,10 @@ func Paginate(r *http.Request, db *gorm.DB, out interface{}, response *APIRespon
 		"total_records": count,
 		"total_pages":   totalPages,
 		"remaining":     remaining,
+		"next":          nil,
+	}
+	if page < totalPages {
+		response.Meta["next"] = pageURL(r, page+1)
 	}
 	scopeFunc := func(db *gorm.DB) *gorm.DB {
 		// preload specified relationships
@@ -97,3 +103,11 @@ func Paginate(r *http.Request, db *gorm.DB, out interface{}, response *APIRespon
 
 	return scopeFunc, nil
 }
+
+// pageURL returns the absolute URL of the page of the paginated request, it keeps the other query parameters.
+// The URL starts with the APP_URL like the links built by packages.URL, utils can't import the config.
+func pageURL(r *http.Request, page int) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	return strings.TrimRight(Env("APP_URL", "http://localhost"), "/") + r.URL.Path + "?" + query.Encode()
+}
@@ -96,26 +96,3 @@ func GeneratePassword(length int) (string, error) {
 	}
 	return password, nil
 }
-
-// IsStrongPassword checks if the given password is strong, meaning it is at least 8 characters long
-// and contains at least one lowercase letter, one uppercase letter, one number, and one symbol
-// func IsStrongPassword(password string) bool {
-// 	hasMinLength := len(password) >= minLength
-// 	hasLowercase := false
-// 	hasUppercase := false
-// 	hasNumber := false
-// 	hasSymbol := false
-// 	for _, char := range password {
-// 		switch {
-// 		case 'a' <= char && char <= 'z':
-// 			hasLowercase = true
-// 		case 'A' <= char && char <= 'Z':
-// 			hasUppercase = true
-// 		case '0' <= char && char <= '9':
-// 			hasNumber = true
-// 		case symbols[0] <= char && char <= symbols[len(symbols)-1]:
-// 			hasSymbol = true
-// 		}
-// 	}
-// 	return hasMinLength && hasLowercase && hasUppercase && hasNumber && hasSymbol
-// }
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// requestIDKey is the context key of the request ID.
+type requestIDKey struct{}
+
+// NewRequestID returns a random request ID of 32 hex characters.
+func NewRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// WithRequestID returns a copy of ctx that carries the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID the RequestIDMiddleware gave the request, or an empty string.
+//
+// Example usage:
+//
+//	log.Printf("request %s failed: %v", utils.RequestID(r.Context()), err)
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ResponseWriter wraps an http.ResponseWriter and remembers the status code and the number of bytes of
+// the response, for middlewares that log or recover requests.
+type ResponseWriter struct {
+	http.ResponseWriter
+	Status int
+	Bytes  int
+}
+
+// NewResponseWriter wraps w, or returns it if it is already wrapped.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// Written reports whether the status code of the response was sent.
+func (w *ResponseWriter) Written() bool {
+	return w.Status != 0
+}
+
+func (w *ResponseWriter) WriteHeader(status int) {
+	if w.Status == 0 {
+		w.Status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	if w.Status == 0 {
+		w.Status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.Bytes += n
+	return n, err
+}
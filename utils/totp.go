@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// totpPeriod is the time step, in seconds, used to derive TOTP codes (RFC 6238).
+	totpPeriod = 30
+	// totpDigits is the number of digits in a generated TOTP code.
+	totpDigits = 6
+	// totpSkew is the number of time steps before and after the current one that are still accepted.
+	totpSkew = 1
+)
+
+// GenerateTOTPSecret generates a new random base32 encoded secret suitable for TOTP.
+//
+// The secret is 160 bits long as recommended by RFC 4226 and is encoded without padding
+// so it can be typed into authenticator apps that do not support padding characters.
+//
+// Example usage:
+//
+//	secret, err := GenerateTOTPSecret()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Returns:
+//   - string: the base32 encoded secret
+//   - error: an error, if any, that occurred while reading random bytes
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+// TOTPProvisioningURI builds the otpauth:// URI that authenticator apps use to enroll a secret.
+//
+// The URI is usually rendered as a QR code by the client.
+//
+// Example usage:
+//
+//	uri := TOTPProvisioningURI("Gonga", "john@example.com", secret)
+//	// otpauth://totp/Gonga:john@example.com?algorithm=SHA1&digits=6&issuer=Gonga&period=30&secret=...
+//
+// Parameters:
+//   - issuer (string): the name of the application shown in the authenticator app
+//   - account (string): the account name, usually the user's email or username
+//   - secret (string): the base32 encoded secret
+//
+// Returns:
+//   - string: the provisioning URI
+func TOTPProvisioningURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// TOTPCode returns the TOTP code of the given secret for the given time step counter.
+//
+// Parameters:
+//   - secret (string): the base32 encoded secret
+//   - counter (int64): the time step counter, see TOTPCounter
+//
+// Returns:
+//   - string: the zero padded code
+//   - error: an error if the secret is not valid base32
+func TOTPCode(secret string, counter int64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation as described in RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	binCode := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, binCode%mod), nil
+}
+
+// TOTPCounter returns the RFC 6238 time step counter for the given time.
+func TOTPCounter(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// VerifyTOTP checks the given code against the secret, allowing a small clock skew.
+//
+// On success it returns the time step counter that matched so callers can reject
+// a code that has already been used (see RFC 6238 section 5.2).
+//
+// Example usage:
+//
+//	counter, ok := VerifyTOTP(user.TwoFactorSecret, "123456", time.Now())
+//	if !ok || counter <= user.TwoFactorLastCounter {
+//	    // reject the code
+//	}
+//
+// Parameters:
+//   - secret (string): the base32 encoded secret
+//   - code (string): the code provided by the user
+//   - now (time.Time): the time to verify the code against
+//
+// Returns:
+//   - int64: the matching time step counter
+//   - bool: whether the code is valid
+func VerifyTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := TOTPCounter(now)
+	for step := -totpSkew; step <= totpSkew; step++ {
+		counter := current + int64(step)
+		expected, err := TOTPCode(secret, counter)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// VerifyTOTPOnce checks the code like VerifyTOTP and also rejects a code whose time step is not after
+// lastCounter, the time step of the last code the user logged in with. A code can't be replayed, and
+// neither can an older code of the skew window once a newer one was used.
+//
+// Example usage:
+//
+//	counter, ok := VerifyTOTPOnce(user.TwoFactorSecret, "123456", user.TwoFactorLastCounter, time.Now())
+//	if ok {
+//	    // store counter as the user's last counter
+//	}
+func VerifyTOTPOnce(secret, code string, lastCounter int64, now time.Time) (int64, bool) {
+	counter, ok := VerifyTOTP(secret, code, now)
+	if !ok || counter <= lastCounter {
+		return 0, false
+	}
+	return counter, true
+}
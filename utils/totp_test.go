@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890", in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// RFC 6238 Appendix B lists 8 digit codes, ours are their last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, test := range tests {
+		code, err := TOTPCode(rfc6238Secret, TOTPCounter(time.Unix(test.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode at %d: %v", test.unix, err)
+		}
+		if code != test.code {
+			t.Errorf("TOTPCode at %d = %s, want %s", test.unix, code, test.code)
+		}
+	}
+}
+
+func TestVerifyTOTPSkewWindow(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TOTPCounter(now)
+
+	tests := []struct {
+		step int64
+		ok   bool
+	}{
+		{-2, false},
+		{-1, true},
+		{0, true},
+		{1, true},
+		{2, false},
+	}
+	for _, test := range tests {
+		code, err := TOTPCode(rfc6238Secret, current+test.step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counter, ok := VerifyTOTP(rfc6238Secret, code, now)
+		if ok != test.ok {
+			t.Errorf("VerifyTOTP of step %+d = %v, want %v", test.step, ok, test.ok)
+		} else if ok && counter != current+test.step {
+			t.Errorf("VerifyTOTP of step %+d matched counter %d, want %d", test.step, counter, current+test.step)
+		}
+	}
+}
+
+func TestVerifyTOTPOnceRejectsReplays(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := TOTPCounter(now)
+	code, _ := TOTPCode(rfc6238Secret, current)
+	previous, _ := TOTPCode(rfc6238Secret, current-1)
+
+	tests := []struct {
+		name        string
+		code        string
+		lastCounter int64
+		ok          bool
+	}{
+		{"first use", code, 0, true},
+		{"replayed code", code, current, false},
+		{"older code after a newer one", previous, current, false},
+		{"code after an older one", code, current - 1, true},
+		{"wrong code", "000000", 0, false},
+	}
+	for _, test := range tests {
+		counter, ok := VerifyTOTPOnce(rfc6238Secret, test.code, test.lastCounter, now)
+		if ok != test.ok {
+			t.Errorf("%s: VerifyTOTPOnce = %v, want %v", test.name, ok, test.ok)
+		} else if ok && counter <= test.lastCounter {
+			t.Errorf("%s: VerifyTOTPOnce matched counter %d, not after %d", test.name, counter, test.lastCounter)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package utils
 
+import "time"
+
 type MalformedRequest struct {
 	status int
 	msg    string
@@ -34,4 +36,13 @@ type APIError struct {
 	Suggestion string                 `json:"suggestion,omitempty" example:"Try again later."`
 }
 
+// Tombstone takes the place of a post or comment that moderators removed or hid, so that clients
+// can tell removed content from content that never existed.
+type Tombstone struct {
+	ID        uint      `json:"id" example:"1"`
+	Type      string    `json:"type" example:"posts"`
+	Status    string    `json:"status" example:"removed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type ContextKey string
@@ -99,10 +99,12 @@ var Validation = map[string]interface{}{
     "not_regex" : "The :attribute format is invalid.",
     "numeric" : "The :attribute must be a number.",
     "password" : map[string]string{
+        "min" : "The :attribute must be at least :min characters.",
         "letters" : "The :attribute must contain at least one letter.",
         "mixed" : "The :attribute must contain at least one uppercase and one lowercase letter.",
         "numbers" : "The :attribute must contain at least one number.",
         "symbols" : "The :attribute must contain at least one symbol.",
+        "similar" : "The :attribute must not contain your :other.",
         "uncompromised" : "The given :attribute has appeared in a data leak. Please choose a different :attribute.",
 	},
     "present" : "The :attribute field must be present.",
@@ -172,4 +174,32 @@ func ValidationMessage(e validator.FieldError) string {
     message = strings.Replace(message, ":date", e.Param(), -1)
 
 	return message
-}
\ No newline at end of file
+}
+
+// PasswordValidationMessage returns the formatted message for a failed password policy rule.
+// The rule is one of the keys of Validation["password"], such as "mixed" or "uncompromised".
+//
+// Example usage:
+//
+//	message := PasswordValidationMessage("min", "Password", "12")
+//	// The Password must be at least 12 characters.
+//
+// Parameters:
+//   - rule (string): The password rule that failed.
+//   - attribute (string): The name of the password field.
+//   - param (string): The rule parameter, the minimum length for "min" or the matched field for "similar".
+//
+// Returns:
+//  - message (string): The formatted error message, or an empty string for an unknown rule.
+func PasswordValidationMessage(rule, attribute, param string) string {
+	message, ok := Validation["password"].(map[string]string)[rule]
+	if !ok {
+		return ""
+	}
+
+	message = strings.Replace(message, ":attribute", attribute, -1)
+	message = strings.Replace(message, ":other", param, -1)
+	message = strings.Replace(message, ":min", param, -1)
+
+	return message
+}